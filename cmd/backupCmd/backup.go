@@ -0,0 +1,110 @@
+package backupCmd
+
+import (
+	"devops_tools/internal/catalog"
+	"devops_tools/internal/i18n"
+	"devops_tools/internal/mysql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Manage the backup catalog of MySQL and TiDB backups",
+}
+var backupRoot string
+var policy catalog.Policy
+var dryRun bool
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the backups recorded in the catalog",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := catalog.Load(backupRoot)
+		if err != nil {
+			return err
+		}
+		return printEntries(c.Entries)
+	},
+}
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete backups outside the grandfather-father-son retention policy",
+	Long: `Delete backups outside the grandfather-father-son retention policy.
+
+For every instance and database, the newest successful backup of each of the last
+--keep-daily days, --keep-weekly ISO weeks and --keep-monthly months is kept.
+Failed backups are always pruned. BR backups on object storage are only removed from the catalog.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+		expired, err := catalog.Prune(backupRoot, policy, dryRun)
+		if printErr := printEntries(expired); printErr != nil {
+			return printErr
+		}
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			fmt.Print(i18n.T("catalog.would_prune", len(expired)))
+		} else {
+			fmt.Print(i18n.T("catalog.pruned", len(expired)))
+		}
+		return nil
+	},
+}
+
+func printEntries(entries []catalog.Entry) error {
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, i18n.Header("TIME", "ENGINE", "INSTANCE", "DATABASE", "STATUS", "SIZE", "CHECKSUM", "VERIFIED", "PATH"))
+	for _, e := range entries {
+		dbs := "all"
+		if len(e.Databases) > 0 {
+			dbs = strings.Join(e.Databases, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Format("2006-01-02 15:04:05"), e.Engine, e.Instance, dbs, e.Status,
+			mysql.FormatSize(e.Size), shortChecksum(e.Checksum), verified(e.Verification), e.Path)
+	}
+	return w.Flush()
+}
+
+// verified 显示最近一次恢复验证的结果和日期，未验证时为 -
+func verified(v *catalog.Verification) string {
+	switch {
+	case v == nil:
+		return "-"
+	case v.Passed:
+		return "passed " + v.Time.Format("2006-01-02")
+	default:
+		return "failed " + v.Time.Format("2006-01-02")
+	}
+}
+
+// shortChecksum 表格中只显示校验和的前 12 位
+func shortChecksum(sum string) string {
+	sum = strings.TrimPrefix(sum, "sha256:")
+	if len(sum) > 12 {
+		return sum[:12]
+	}
+	return sum
+}
+
+func BackupCmd() *cobra.Command {
+	return backupCmd
+}
+func init() {
+	backupCmd.PersistentFlags().StringVar(&backupRoot, "backup-root", "/backup", "backup root directory holding catalog.json")
+	backupCmd.AddCommand(listCmd)
+	backupCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().IntVar(&policy.Daily, "keep-daily", 7, "number of days to keep the newest daily backup for")
+	pruneCmd.Flags().IntVar(&policy.Weekly, "keep-weekly", 4, "number of ISO weeks to keep the newest weekly backup for")
+	pruneCmd.Flags().IntVar(&policy.Monthly, "keep-monthly", 6, "number of months to keep the newest monthly backup for")
+	pruneCmd.Flags().BoolVar(&dryRun, "dry-run", false, "only list the backups that would be deleted")
+}
@@ -1,23 +1,53 @@
 package clusterCmd
 
 import (
+	"context"
 	"devops_tools/internal/api"
 	"devops_tools/internal/cluster"
+	"devops_tools/internal/i18n"
+	"devops_tools/internal/install"
 	"github.com/spf13/cobra"
-	"log"
+	"os/signal"
+	"syscall"
 )
 
 var cleanStorageCmd = &cobra.Command{
 	Use:   "clean-storage",
 	Short: "clean unused StorageClass and PV resource",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := api.NewClient()
 		if err != nil {
-			log.Printf("Error: %v", err)
-			return
+			return err
 		}
-		if err := cluster.CleanStorageResources(client); err != nil {
-			log.Printf("cleanup failed: %v", err)
+		// 预检删除权限，避免清理到一半才遇到 403
+		if err := checkPermissions(client, install.CleanStorageRules()); err != nil {
+			return err
 		}
+		// 收到 SIGINT/SIGTERM 后不再开始新的删除，已开始的删除执行完后退出
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		snap, err := cluster.LoadSnapshot(ctx, client)
+		if err != nil {
+			return err
+		}
+		for _, r := range cleanReasons {
+			cleanOpts.Reasons = append(cleanOpts.Reasons, cluster.CleanupReason(r))
+		}
+		if err := cluster.CleanStorageResources(ctx, client, snap, cleanOpts); err != nil {
+			return i18n.Errorf("cleanup.failed", err)
+		}
+		return nil
+	},
+}
+var cleanPlanCmd = &cobra.Command{
+	Use:   "clean-plan",
+	Short: "Show StorageClass and PV resource that clean-storage would delete",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snap, err := loadSnapshot()
+		if err != nil {
+			return err
+		}
+		return cluster.PrintCleanupPlan(cluster.PlanCleanup(snap), fileinfo)
 	},
 }
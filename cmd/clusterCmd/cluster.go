@@ -1,7 +1,11 @@
 package clusterCmd
 
 import (
+	"devops_tools/internal/api"
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/completion"
 	"github.com/spf13/cobra"
+	"time"
 )
 
 var clusterCmd = &cobra.Command{
@@ -9,14 +13,81 @@ var clusterCmd = &cobra.Command{
 	Short: "cluster commands",
 }
 var fileinfo string
+var cleanOpts cluster.CleanOptions
+var cleanReasons []string
+var fromSnapshot string
+var outputFormat string
+var skipPreflight bool
+var pvFilter cluster.PVFilter
+var workloadFilter cluster.WorkloadFilter
+var imageOpts cluster.ImageOptions
+var rightsizingOpts cluster.RightsizingOptions
+var window, interval time.Duration
 
 func ClusterCmd() *cobra.Command {
 	return clusterCmd
 }
 func init() {
+	clusterCmd.PersistentFlags().Float32Var(&api.QPS, "qps", api.QPS, "client-side QPS limit for API requests")
+	clusterCmd.PersistentFlags().IntVar(&api.Burst, "burst", api.Burst, "client-side burst limit for API requests")
+	clusterCmd.PersistentFlags().BoolVar(&skipPreflight, "skip-preflight", false, "skip the RBAC permission preflight check")
 	clusterCmd.AddCommand(getStorageClassCmd)
 	getStorageClassCmd.Flags().StringVarP(&fileinfo, "file", "f", "", "file path")
+	getStorageClassCmd.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "load resources from a snapshot file or directory instead of the cluster")
 	clusterCmd.AddCommand(getPVCmd)
 	getPVCmd.Flags().StringVarP(&fileinfo, "file", "f", "", "file path")
-	//clusterCmd.AddCommand(cleanStorageCmd)
+	getPVCmd.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "load resources from a snapshot file or directory instead of the cluster")
+	getPVCmd.Flags().StringVar(&pvFilter.StorageClass, "sc", "", "only list PVs of this StorageClass")
+	getPVCmd.Flags().StringVarP(&pvFilter.Namespace, "namespace", "n", "", "only list PVs bound to PVCs in this namespace")
+	_ = getPVCmd.RegisterFlagCompletionFunc("sc", completion.StorageClasses)
+	_ = getPVCmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	clusterCmd.AddCommand(getNamespaceCmd)
+	getNamespaceCmd.Flags().StringVarP(&fileinfo, "file", "f", "", "file path")
+	getNamespaceCmd.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "load resources from a snapshot file or directory instead of the cluster")
+	clusterCmd.AddCommand(getNodeCmd)
+	getNodeCmd.Flags().StringVarP(&fileinfo, "file", "f", "", "file path")
+	getNodeCmd.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "load resources from a snapshot file or directory instead of the cluster")
+	clusterCmd.AddCommand(getWorkloadCmd)
+	getWorkloadCmd.Flags().StringVarP(&fileinfo, "file", "f", "", "file path")
+	getWorkloadCmd.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "load resources from a snapshot file or directory instead of the cluster")
+	getWorkloadCmd.Flags().StringVarP(&workloadFilter.Namespace, "namespace", "n", "", "only list workloads in this namespace")
+	getWorkloadCmd.Flags().StringSliceVar(&workloadFilter.Kinds, "kind", nil, "only list these kinds: Deployment, StatefulSet, DaemonSet, CronJob, Job")
+	_ = getWorkloadCmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	_ = getWorkloadCmd.RegisterFlagCompletionFunc("kind", completion.Fixed(cluster.KindDeployment, cluster.KindStatefulSet, cluster.KindDaemonSet, cluster.KindCronJob, cluster.KindJob))
+	clusterCmd.AddCommand(getImagesCmd)
+	getImagesCmd.Flags().StringVarP(&fileinfo, "file", "f", "", "file path")
+	getImagesCmd.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "load resources from a snapshot file or directory instead of the cluster")
+	getImagesCmd.Flags().StringVarP(&imageOpts.Namespace, "namespace", "n", "", "only include pods and workloads in this namespace")
+	getImagesCmd.Flags().StringSliceVar(&imageOpts.AllowedRegistries, "allowed-registry", nil, "allowed registries or registry path prefixes, images elsewhere are flagged")
+	getImagesCmd.Flags().BoolVar(&imageOpts.IssuesOnly, "issues-only", false, "only list images with issues")
+	_ = getImagesCmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	clusterCmd.AddCommand(rightsizingCmd)
+	rightsizingCmd.Flags().StringVarP(&fileinfo, "file", "f", "", "file path")
+	rightsizingCmd.Flags().StringVarP(&rightsizingOpts.Namespace, "namespace", "n", "", "only analyze workloads in this namespace")
+	rightsizingCmd.Flags().DurationVar(&window, "window", 0, "collect samples over this window instead of a single reading")
+	rightsizingCmd.Flags().DurationVar(&interval, "interval", 30*time.Second, "interval between samples within --window")
+	rightsizingCmd.Flags().Float64Var(&rightsizingOpts.Headroom, "headroom", 0.2, "fraction added on top of observed usage for recommendations")
+	_ = rightsizingCmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	clusterCmd.AddCommand(cleanStorageCmd)
+	cleanStorageCmd.Flags().IntVarP(&cleanOpts.Concurrency, "concurrency", "c", 4, "number of concurrent delete workers")
+	cleanStorageCmd.Flags().StringVar(&cleanOpts.BackupDir, "backup-dir", "/data/storage-clean", "root directory for resource YAML backups taken before deletion")
+	cleanStorageCmd.Flags().BoolVar(&cleanOpts.DryRun, "dry-run", false, "only log the resources that would be deleted")
+	cleanStorageCmd.Flags().StringSliceVar(&cleanReasons, "reason", nil, "only clean resources with these reasons, can be repeated; NodeMissing local PVs are only cleaned when listed")
+	_ = cleanStorageCmd.RegisterFlagCompletionFunc("reason", completion.Fixed(string(cluster.ReasonUnusedStorageClass), string(cluster.ReasonAvailable), string(cluster.ReasonReleasedNoClaim), string(cluster.ReasonPVCMissing), string(cluster.ReasonUIDMismatch), string(cluster.ReasonNodeMissing)))
+	clusterCmd.AddCommand(cleanPlanCmd)
+	cleanPlanCmd.Flags().StringVarP(&fileinfo, "file", "f", "", "file path")
+	cleanPlanCmd.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "load resources from a snapshot file or directory instead of the cluster")
+	clusterCmd.AddCommand(storageDiffCmd)
+	storageDiffCmd.Flags().StringVarP(&fileinfo, "file", "f", "", "file path")
+	storageDiffCmd.Flags().StringVarP(&outputFormat, "output", "o", "table", "output format: table|json")
+	_ = storageDiffCmd.RegisterFlagCompletionFunc("output", completion.Fixed("table", "json"))
+	clusterCmd.AddCommand(storageReportCmd)
+	storageReportCmd.Flags().StringVarP(&fileinfo, "file", "f", "", "file path")
+	storageReportCmd.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "load resources from a snapshot file or directory instead of the cluster")
+	storageReportCmd.Flags().StringVar(&reportFormat, "format", "xlsx", "report format: xlsx|html|markdown")
+	_ = storageReportCmd.RegisterFlagCompletionFunc("format", completion.Fixed("xlsx", "html", "markdown"))
+	storageReportCmd.Flags().StringVar(&reportTemplate, "template", "", "custom Go template file for html/markdown format")
+	clusterCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+	snapshotSaveCmd.Flags().StringVarP(&snapshotDir, "dir", "d", "", "output directory (default snapshot-<timestamp>)")
 }
@@ -0,0 +1,67 @@
+package clusterCmd
+
+import (
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/completion"
+	"github.com/spf13/cobra"
+)
+
+var getNamespaceCmd = &cobra.Command{
+	Use:               "get-ns [name...]",
+	Short:             "Get namespaces with bound StorageClasses, quota usage, PVCs and workload counts",
+	ValidArgsFunction: completion.Namespaces,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snap, err := loadSnapshot()
+		if err != nil {
+			return err
+		}
+		return cluster.GetNamespaceInfo(snap, fileinfo, args)
+	},
+}
+var getNodeCmd = &cobra.Command{
+	Use:               "get-node [name...]",
+	Short:             "Get nodes with versions, allocatable vs requested resources, pressure conditions, taints and local PVs",
+	ValidArgsFunction: completion.Nodes,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snap, err := loadSnapshot()
+		if err != nil {
+			return err
+		}
+		return cluster.GetNodeInfo(snap, fileinfo, args)
+	},
+}
+var getWorkloadCmd = &cobra.Command{
+	Use:   "get-workload",
+	Short: "Get Deployments, StatefulSets, DaemonSets, CronJobs and Jobs with images, resources, PVCs and node spread",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := workloadFilter.Validate(); err != nil {
+			return err
+		}
+		snap, err := loadSnapshot()
+		if err != nil {
+			return err
+		}
+		return cluster.GetWorkloadInfo(snap, fileinfo, workloadFilter)
+	},
+}
+var getImagesCmd = &cobra.Command{
+	Use:   "get-images",
+	Short: "Aggregate container images across pods and workload templates and flag tag and registry policy issues",
+	Long: `Aggregate container and init container images across pods and workload templates
+with usage count, namespaces, pull policy and the digest reported in pod status.
+
+Images are flagged when they use the latest tag without a digest (latest-tag), come
+from a registry outside --allowed-registry (registry-not-allowed), or only appear in
+Deployment, StatefulSet or DaemonSet templates without a running pod (not-running).
+Docker Hub official images are matched as docker.io/library/<name>, so redis is
+allowed by --allowed-registry docker.io/library.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snap, err := loadSnapshot()
+		if err != nil {
+			return err
+		}
+		return cluster.GetImageInfo(snap, fileinfo, imageOpts)
+	},
+}
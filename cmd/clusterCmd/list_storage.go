@@ -1,43 +1,68 @@
 package clusterCmd
 
 import (
+	"context"
 	"devops_tools/internal/api"
 	"devops_tools/internal/cluster"
+	"devops_tools/internal/completion"
+	"devops_tools/internal/install"
+	"devops_tools/internal/preflight"
 	"github.com/spf13/cobra"
-	"log"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/kubernetes"
+	"os"
 )
 
 var getStorageClassCmd = &cobra.Command{
-	Use:   "get-sc",
-	Short: "Get storageclass resource",
-	Args:  cobra.NoArgs,
-	Run: func(cmd *cobra.Command, args []string) {
-		client, err := api.NewClient()
+	Use:               "get-sc [name...]",
+	Short:             "Get storageclass resource",
+	ValidArgsFunction: completion.StorageClasses,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snap, err := loadSnapshot()
 		if err != nil {
-			log.Printf("Error: %v", err)
-			return
-		}
-		err = cluster.GetStorageClassInfo(client, fileinfo)
-		if err != nil {
-			log.Printf("Error: %v", err)
-			return
+			return err
 		}
+		return cluster.GetStorageClassInfo(snap, fileinfo, args)
 	},
 }
 var getPVCmd = &cobra.Command{
-	Use:   "get-pv",
-	Short: "Get pv resource",
-	Args:  cobra.NoArgs,
-	Run: func(cmd *cobra.Command, args []string) {
-		client, err := api.NewClient()
+	Use:               "get-pv [name...]",
+	Short:             "Get pv resource",
+	ValidArgsFunction: completion.PersistentVolumes,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snap, err := loadSnapshot()
 		if err != nil {
-			log.Printf("Error: %v", err)
-			return
-		}
-		err = cluster.GetPersistentVolumeInfo(client, fileinfo)
-		if err != nil {
-			log.Printf("Error: %v", err)
-			return
+			return err
 		}
+		pvFilter.Names = args
+		return cluster.GetPersistentVolumeInfo(snap, fileinfo, pvFilter)
 	},
 }
+
+// loadSnapshot 拉取一次资源快照，指定 --from-snapshot 时从本地文件离线加载
+func loadSnapshot() (*cluster.Snapshot, error) {
+	return loadSnapshotFrom(fromSnapshot)
+}
+
+// loadSnapshotFrom path 为空时连接集群拉取，否则从快照文件或目录加载
+func loadSnapshotFrom(path string) (*cluster.Snapshot, error) {
+	if path != "" {
+		return cluster.LoadSnapshotFromPath(path)
+	}
+	client, err := api.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPermissions(client, install.SnapshotRules()); err != nil {
+		return nil, err
+	}
+	return cluster.LoadSnapshot(context.Background(), client)
+}
+
+// checkPermissions 执行命令前通过 SelfSubjectAccessReview 预检权限，--skip-preflight 时跳过
+func checkPermissions(client kubernetes.Interface, rules []rbacv1.PolicyRule) error {
+	if skipPreflight {
+		return nil
+	}
+	return preflight.Require(context.Background(), client, preflight.FromRules(rules, ""), os.Stderr)
+}
@@ -0,0 +1,65 @@
+package clusterCmd
+
+import (
+	"context"
+	"devops_tools/internal/api"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/i18n"
+	"devops_tools/internal/install"
+	"fmt"
+	"github.com/spf13/cobra"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"os"
+)
+
+var rightsizingCmd = &cobra.Command{
+	Use:   "rightsizing",
+	Short: "Compare container requests and limits with metrics-server usage and recommend new values",
+	Long: `Compare container requests and limits with usage from metrics.k8s.io and recommend
+new values per workload container.
+
+Without --window a single reading is used. With --window, usage is sampled every
+--interval over the window. CPU requests are recommended from the P95 and memory
+requests from the peak, both plus --headroom. Memory limits get twice the headroom,
+and CPU limits are only recommended (twice the request) when one is already set.
+
+Samples are matched to pods in a snapshot taken after sampling. Usage of pods that
+were deleted during the window, for example by a rolling update, is not counted;
+their number is printed as a warning.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if rightsizingOpts.Headroom < 0 {
+			return apperr.ValidationError(i18n.Errorf("rightsizing.invalid_headroom", rightsizingOpts.Headroom))
+		}
+		if window > 0 && interval <= 0 {
+			return apperr.ValidationError(i18n.Errorf("rightsizing.invalid_interval", interval))
+		}
+		client, err := api.NewClient()
+		if err != nil {
+			return err
+		}
+		rules := append(install.SnapshotRules(), rbacv1.PolicyRule{APIGroups: []string{"metrics.k8s.io"}, Resources: []string{"pods"}, Verbs: []string{"list"}})
+		if err := checkPermissions(client, rules); err != nil {
+			return err
+		}
+		metrics, err := api.NewMetricsClient()
+		if err != nil {
+			return err
+		}
+		ctx := context.Background()
+		samples, err := cluster.CollectUsage(ctx, metrics, rightsizingOpts.Namespace, window, interval, os.Stderr)
+		if err != nil {
+			return err
+		}
+		// 采样结束后再加载快照，窗口内新建的 Pod 也能归属到工作负载
+		snap, err := cluster.LoadSnapshot(ctx, client)
+		if err != nil {
+			return err
+		}
+		if unmatched := samples.Unmatched(snap); len(unmatched) > 0 {
+			fmt.Fprint(os.Stderr, i18n.T("rightsizing.unmatched", len(unmatched)))
+		}
+		return cluster.PrintRightsizing(snap.Rightsizing(samples, rightsizingOpts), fileinfo)
+	},
+}
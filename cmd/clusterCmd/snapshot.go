@@ -0,0 +1,36 @@
+package clusterCmd
+
+import (
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/i18n"
+	"fmt"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+var snapshotDir string
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "snapshot commands",
+}
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save",
+	Short: "Save storage related resources for offline analysis with --from-snapshot",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snap, err := loadSnapshot()
+		if err != nil {
+			return err
+		}
+		dir := snapshotDir
+		if dir == "" {
+			dir = "snapshot-" + time.Now().Format("20060102150405")
+		}
+		if err := cluster.SaveSnapshot(snap, dir); err != nil {
+			return err
+		}
+		fmt.Print(i18n.T("snapshot.saved", dir))
+		return nil
+	},
+}
@@ -0,0 +1,28 @@
+package clusterCmd
+
+import (
+	"devops_tools/internal/cluster"
+	"github.com/spf13/cobra"
+)
+
+var storageDiffCmd = &cobra.Command{
+	Use:   "storage-diff <snapshotA> [snapshotB]",
+	Short: "Compare PV and StorageClass inventory between two snapshots, or a snapshot and the live cluster",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		before, err := loadSnapshotFrom(args[0])
+		if err != nil {
+			return err
+		}
+		// 未指定第二个快照时与当前集群比较
+		afterPath := ""
+		if len(args) == 2 {
+			afterPath = args[1]
+		}
+		after, err := loadSnapshotFrom(afterPath)
+		if err != nil {
+			return err
+		}
+		return cluster.PrintStorageDiff(cluster.DiffSnapshots(before, after), outputFormat, fileinfo)
+	},
+}
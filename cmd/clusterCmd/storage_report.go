@@ -0,0 +1,53 @@
+package clusterCmd
+
+import (
+	"bytes"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/i18n"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var reportFormat string
+var reportTemplate string
+
+var storageReportCmd = &cobra.Command{
+	Use:   "storage-report",
+	Short: "Export StorageClass, PV, PVC and orphan inventory as an Excel workbook, HTML or Markdown report",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch reportFormat {
+		case "xlsx":
+			if fileinfo == "" {
+				return apperr.ValidationError(i18n.Errorf("report.file_required"))
+			}
+		case "html", "markdown", "md":
+		default:
+			return apperr.ValidationError(i18n.Errorf("report.unsupported", reportFormat))
+		}
+		snap, err := loadSnapshot()
+		if err != nil {
+			return err
+		}
+		if reportFormat == "xlsx" {
+			return cluster.WriteStorageReport(snap, fileinfo)
+		}
+
+		// 先渲染到内存，模板出错时不留下空文件；html/markdown 未指定文件时输出到标准输出
+		var buf bytes.Buffer
+		if err := cluster.RenderStorageReport(snap, reportFormat, reportTemplate, &buf); err != nil {
+			return err
+		}
+		if fileinfo == "" {
+			_, err = buf.WriteTo(os.Stdout)
+			return err
+		}
+		if err := os.WriteFile(fileinfo, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		fmt.Print(i18n.T("report.file_written", fileinfo))
+		return nil
+	},
+}
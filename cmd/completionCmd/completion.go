@@ -0,0 +1,43 @@
+package completionCmd
+
+import (
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish|powershell",
+	Short: "Generate the autocompletion script for the specified shell",
+	Long: `Generate the autocompletion script for devops-tool.
+
+  bash:       source <(devops-tool completion bash)
+  zsh:        devops-tool completion zsh > "${fpath[1]}/_devops-tool"
+  fish:       devops-tool completion fish > ~/.config/fish/completions/devops-tool.fish
+  powershell: devops-tool completion powershell | Out-String | Invoke-Expression
+
+StorageClass, PV, namespace and context names are completed from the current cluster.`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	// 生成脚本不需要读取配置文件
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := cmd.Root()
+		switch args[0] {
+		case "bash":
+			return root.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return root.GenZshCompletion(os.Stdout)
+		case "fish":
+			return root.GenFishCompletion(os.Stdout, true)
+		default:
+			return root.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+	},
+}
+
+func CompletionCmd() *cobra.Command {
+	return completionCmd
+}
@@ -0,0 +1,151 @@
+package configCmd
+
+import (
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/config"
+	"devops_tools/internal/i18n"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and edit the devops-tool config file",
+	// 配置文件有错误时仍然需要能够查看和修复，不执行根命令的配置加载
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+var viewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Print the effective configuration after applying files, profile and environment",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("config")
+		profile, _ := cmd.Flags().GetString("profile")
+		cfg, err := config.Load(path, profile)
+		if err != nil {
+			return apperr.ValidationError(err)
+		}
+		if len(cfg.Sources) == 0 {
+			fmt.Fprint(os.Stderr, i18n.T("config.no_files"))
+		}
+		fmt.Printf("# sources: %s\n", strings.Join(cfg.Sources, ", "))
+		if cfg.Profile != "" {
+			fmt.Printf("# profile: %s\n", cfg.Profile)
+		}
+		out, err := yaml.Marshal(cfg.Settings)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	},
+}
+var setCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a dotted key such as cleanup.concurrency or profiles.prod.context in the config file",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("config")
+		if path == "" {
+			path = config.UserPath()
+		}
+		if err := config.Set(path, args[0], args[1]); err != nil {
+			return apperr.ValidationError(err)
+		}
+		return nil
+	},
+}
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check config files for unknown keys and invalid values",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("config")
+		paths := []string{path}
+		if path == "" {
+			paths = nil
+			for _, p := range []string{config.SystemPath, config.UserPath()} {
+				if _, err := os.Stat(p); err == nil {
+					paths = append(paths, p)
+				}
+			}
+		}
+		if len(paths) == 0 {
+			fmt.Fprint(os.Stderr, i18n.T("config.no_files"))
+			return nil
+		}
+		var failed int
+		for _, p := range paths {
+			errs := config.ValidateFile(p)
+			for _, err := range errs {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", p, err)
+			}
+			if len(errs) > 0 {
+				failed++
+				continue
+			}
+			fmt.Print(i18n.T("config.valid", p))
+		}
+		if failed > 0 {
+			return apperr.ValidationError(i18n.Errorf("config.invalid_files", failed))
+		}
+		return nil
+	},
+}
+
+var clustersCmd = &cobra.Command{
+	Use:   "clusters",
+	Short: "List the clusters registry, the default cluster sign is marked with *",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("config")
+		profile, _ := cmd.Flags().GetString("profile")
+		cfg, err := config.Load(path, profile)
+		if err != nil {
+			return apperr.ValidationError(err)
+		}
+		current, _ := cmd.Flags().GetString("cluster-sign")
+		if current == "" {
+			current = cfg.ClusterSign
+		}
+		signs := make([]string, 0, len(cfg.Clusters))
+		for sign := range cfg.Clusters {
+			signs = append(signs, sign)
+		}
+		sort.Strings(signs)
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, i18n.Header("CURRENT", "SIGN", "KUBECONFIG", "CONTEXT", "SERVER", "LABELS", "DESCRIPTION"))
+		for _, sign := range signs {
+			c := cfg.Clusters[sign]
+			mark := ""
+			if sign == current {
+				mark = "*"
+			}
+			labels := make([]string, 0, len(c.Labels))
+			for k, v := range c.Labels {
+				labels = append(labels, k+"="+v)
+			}
+			sort.Strings(labels)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", mark, sign, c.Kubeconfig, c.Context, c.Server, strings.Join(labels, ","), c.Description)
+		}
+		return w.Flush()
+	},
+}
+
+func ConfigCmd() *cobra.Command {
+	return configCmd
+}
+func init() {
+	configCmd.AddCommand(viewCmd)
+	configCmd.AddCommand(setCmd)
+	configCmd.AddCommand(validateCmd)
+	configCmd.AddCommand(clustersCmd)
+}
@@ -0,0 +1,77 @@
+package controllerCmd
+
+import (
+	"context"
+	"devops_tools/internal/api"
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/completion"
+	"devops_tools/internal/controller"
+	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+var opts controller.Options
+var reasons []string
+
+var controllerCmd = &cobra.Command{
+	Use:   "controller",
+	Short: "Run storage cleanup on a schedule with leader election",
+	Long: `Run storage cleanup on a schedule with leader election.
+
+The built-in policy runs clean-storage on --schedule. It only logs what it would
+delete until --dry-run=false is set, and --reason limits it to the given cleanup
+reasons. StorageCleanupPolicy objects are checked every --policy-sync-period and
+use their own dryRun and reasons.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, r := range reasons {
+			opts.Clean.Reasons = append(opts.Clean.Reasons, cluster.CleanupReason(r))
+		}
+		client, err := api.NewClient()
+		if err != nil {
+			return err
+		}
+		dynamicClient, err := api.NewDynamicClient()
+		if err != nil {
+			return err
+		}
+		if opts.Identity == "" {
+			opts.Identity, _ = os.Hostname()
+		}
+		c, err := controller.New(client, dynamicClient, opts)
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		return c.Run(ctx)
+	},
+}
+
+func ControllerCmd() *cobra.Command {
+	return controllerCmd
+}
+func init() {
+	namespace := os.Getenv("POD_NAMESPACE")
+	if namespace == "" {
+		namespace = "kube-system"
+	}
+	controllerCmd.Flags().StringVar(&opts.Schedule, "schedule", "0 2 * * *", "cron expression for the built-in cleanup policy, empty to only run StorageCleanupPolicy objects")
+	// 内置策略默认只记录，避免直接部署后每晚删除全部可清理资源
+	controllerCmd.Flags().BoolVar(&opts.Clean.DryRun, "dry-run", true, "only log what the built-in policy would delete, set --dry-run=false to delete")
+	controllerCmd.Flags().StringSliceVar(&reasons, "reason", nil, "only clean resources with these reasons in the built-in policy, can be repeated; NodeMissing local PVs are only cleaned when listed")
+	_ = controllerCmd.RegisterFlagCompletionFunc("reason", completion.Fixed(string(cluster.ReasonUnusedStorageClass), string(cluster.ReasonAvailable), string(cluster.ReasonReleasedNoClaim), string(cluster.ReasonPVCMissing), string(cluster.ReasonUIDMismatch), string(cluster.ReasonNodeMissing)))
+	controllerCmd.Flags().DurationVar(&opts.PolicySyncPeriod, "policy-sync-period", time.Minute, "interval for checking StorageCleanupPolicy schedules, 0 to disable")
+	controllerCmd.Flags().StringVar(&opts.Namespace, "namespace", namespace, "namespace for the leader election lease and pause annotation")
+	_ = controllerCmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	controllerCmd.Flags().StringVar(&opts.LeaseName, "lease-name", "devops-tool-controller", "leader election lease name")
+	controllerCmd.Flags().StringVar(&opts.Identity, "identity", "", "leader election identity (default hostname)")
+	controllerCmd.Flags().StringVar(&opts.HealthAddr, "health-addr", ":8081", "address to serve /healthz and /readyz on")
+	controllerCmd.Flags().IntVarP(&opts.Clean.Concurrency, "concurrency", "c", 4, "number of concurrent delete workers")
+	controllerCmd.Flags().StringVar(&opts.Clean.BackupDir, "backup-dir", "/data/storage-clean", "root directory for resource YAML backups taken before deletion")
+	controllerCmd.Flags().Float32Var(&api.QPS, "qps", api.QPS, "client-side QPS limit for API requests")
+	controllerCmd.Flags().IntVar(&api.Burst, "burst", api.Burst, "client-side burst limit for API requests")
+}
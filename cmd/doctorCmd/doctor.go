@@ -0,0 +1,112 @@
+package doctorCmd
+
+import (
+	"context"
+	"devops_tools/internal/api"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/completion"
+	"devops_tools/internal/i18n"
+	"devops_tools/internal/install"
+	"devops_tools/internal/preflight"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var mode string
+var namespace string
+var backupDir string
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check cluster connectivity, server version, RBAC permissions and backup directory",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, err := install.ParseMode(mode)
+		if err != nil {
+			return err
+		}
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, i18n.Header("CHECK", "STATUS", "DETAIL"))
+		// firstErr 第一个失败的检查，决定退出码
+		var firstErr error
+		report := func(check string, err error, detail string) {
+			status := "OK"
+			if err != nil {
+				status, detail = "FAIL", err.Error()
+				if firstErr == nil {
+					firstErr = i18n.Errorf("doctor.check_failed", check, err)
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", check, status, detail)
+		}
+
+		client, err := api.NewClient()
+		report("connectivity", err, i18n.T("doctor.connectivity_ok"))
+		if err != nil {
+			w.Flush()
+			return firstErr
+		}
+		version, err := client.Discovery().ServerVersion()
+		if err == nil {
+			report("server version", nil, version.GitVersion)
+		} else {
+			report("server version", apperr.ConnectionError(err), "")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		perms := preflight.FromRules(install.ClusterRules(m), "")
+		perms = append(perms, preflight.FromRules(install.NamespaceRules(m), namespace)...)
+		results, err := preflight.Check(ctx, client, perms)
+		missing := preflight.Missing(results)
+		if err == nil && len(missing) > 0 {
+			err = apperr.PermissionError(&preflight.MissingError{Missing: missing})
+		}
+		report("permissions ("+string(m)+")", err, i18n.T("doctor.permissions_ok", len(perms)))
+
+		if m != install.ModeExporter {
+			report("backup dir", checkWritable(backupDir), i18n.T("doctor.backup_writable", backupDir))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if len(missing) > 0 {
+			fmt.Println()
+			if err := preflight.PrintMatrix(os.Stdout, results); err != nil {
+				return err
+			}
+		}
+		return firstErr
+	},
+}
+
+// checkWritable 在目录中创建并删除一个临时文件
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".doctor-")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+func DoctorCmd() *cobra.Command {
+	return doctorCmd
+}
+func init() {
+	doctorCmd.Flags().StringVar(&mode, "mode", string(install.ModeCronJob), "permissions to check: cronjob (clean-storage), controller or exporter (read-only)")
+	doctorCmd.Flags().StringVarP(&namespace, "namespace", "n", "kube-system", "namespace for controller lease permissions")
+	_ = doctorCmd.RegisterFlagCompletionFunc("mode", completion.Fixed(string(install.ModeCronJob), string(install.ModeController), string(install.ModeExporter)))
+	_ = doctorCmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	doctorCmd.Flags().StringVar(&backupDir, "backup-dir", "/data/storage-clean", "backup directory to check for writability")
+	doctorCmd.Flags().Float32Var(&api.QPS, "qps", api.QPS, "client-side QPS limit for API requests")
+	doctorCmd.Flags().IntVar(&api.Burst, "burst", api.Burst, "client-side burst limit for API requests")
+}
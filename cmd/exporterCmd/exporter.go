@@ -0,0 +1,47 @@
+package exporterCmd
+
+import (
+	"context"
+	"devops_tools/internal/api"
+	"devops_tools/internal/exporter"
+	"devops_tools/internal/i18n"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+	"time"
+)
+
+var listenAddr string
+var refreshInterval time.Duration
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Expose storage inventory metrics for Prometheus",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient()
+		if err != nil {
+			return err
+		}
+		e := exporter.New(client)
+		go e.Run(context.Background(), refreshInterval)
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", e.Handler())
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		log.Print(i18n.T("exporter.listening", listenAddr))
+		return http.ListenAndServe(listenAddr, mux)
+	},
+}
+
+func ExporterCmd() *cobra.Command {
+	return exporterCmd
+}
+func init() {
+	exporterCmd.Flags().StringVar(&listenAddr, "listen", ":9100", "address to expose /metrics on")
+	exporterCmd.Flags().DurationVar(&refreshInterval, "interval", time.Minute, "inventory refresh interval")
+	exporterCmd.Flags().Float32Var(&api.QPS, "qps", api.QPS, "client-side QPS limit for API requests")
+	exporterCmd.Flags().IntVar(&api.Burst, "burst", api.Burst, "client-side burst limit for API requests")
+}
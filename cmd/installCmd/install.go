@@ -0,0 +1,49 @@
+package installCmd
+
+import (
+	"devops_tools/internal/completion"
+	"devops_tools/internal/install"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var mode string
+var opts install.Options
+
+var installCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install devops-tool into a cluster",
+}
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Print ServiceAccount, least-privilege RBAC, workload and backup PVC manifests",
+	Long: `Print the manifests needed to run devops-tool in-cluster, ready for kubectl apply -f -.
+
+The ClusterRole only contains the verbs used by the selected mode. Controller mode
+additionally needs the StorageCleanupPolicy CRD from "devops-tool policy crd".`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, err := install.ParseMode(mode)
+		if err != nil {
+			return err
+		}
+		opts.Mode = m
+		return install.Render(opts, os.Stdout)
+	},
+}
+
+func InstallCmd() *cobra.Command {
+	return installCmd
+}
+func init() {
+	installCmd.AddCommand(renderCmd)
+	renderCmd.Flags().StringVar(&mode, "mode", string(install.ModeCronJob), "deployment mode: cronjob, controller or exporter")
+	renderCmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "kube-system", "namespace to install into")
+	_ = renderCmd.RegisterFlagCompletionFunc("mode", completion.Fixed(string(install.ModeCronJob), string(install.ModeController), string(install.ModeExporter)))
+	_ = renderCmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	renderCmd.Flags().StringVar(&opts.Name, "name", "devops-tool", "name of the generated resources")
+	renderCmd.Flags().StringVar(&opts.Image, "image", "devops-tool:latest", "container image")
+	renderCmd.Flags().StringVar(&opts.Schedule, "schedule", "0 2 * * *", "cron expression for cronjob mode")
+	renderCmd.Flags().StringVar(&opts.BackupSize, "backup-size", "10Gi", "size of the backup PVC")
+	renderCmd.Flags().StringVar(&opts.BackupStorageClass, "backup-storage-class", "", "StorageClass of the backup PVC (default cluster default)")
+}
@@ -0,0 +1,315 @@
+package mysqlCmd
+
+import (
+	"context"
+	"devops_tools/internal/api"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/catalog"
+	"devops_tools/internal/completion"
+	"devops_tools/internal/i18n"
+	"devops_tools/internal/mysql"
+	"devops_tools/internal/preflight"
+	"fmt"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var mysqlCmd = &cobra.Command{
+	Use:   "mysql",
+	Short: "MySQL logical backup and restore commands",
+}
+var backupRoot string
+var conn mysql.Conn
+var skipPreflight bool
+var inst mysql.Instance
+var databases []string
+var src, dest mysql.Instance
+var restoreFile string
+var restoreDatabase string
+var verifyOpts mysql.VerifyOptions
+var dockerContainer string
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Run mysqldump in the instance pod and write gzipped dumps under the backup root",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireInstance(inst, "namespace", "name"); err != nil {
+			return err
+		}
+		client, err := connect(inst.Namespace)
+		if err != nil {
+			return err
+		}
+		dumps, err := mysql.Backup(context.Background(), client, inst, mysql.BackupOptions{Conn: conn, Databases: databases, Dir: backupRoot})
+		if printErr := printDumps(dumps); printErr != nil {
+			return printErr
+		}
+		return err
+	},
+}
+var listBackupCmd = &cobra.Command{
+	Use:   "list-backup",
+	Short: "List dumps under the backup root with size and timestamp",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dumps, err := mysql.ListBackups(backupRoot, inst)
+		if err != nil {
+			return err
+		}
+		return printDumps(dumps)
+	},
+}
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Stream a dump into a MySQL instance",
+	Long: `Stream a dump into a MySQL instance.
+
+Without --file the latest backup of --database taken from the source instance is used.
+With --file and a source instance, the file must have been taken from that instance.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if dest.Namespace == "" {
+			dest.Namespace = src.Namespace
+		}
+		if err := requireInstance(dest, "dest-namespace", "dest-name"); err != nil {
+			return err
+		}
+		file, err := resolveDump()
+		if err != nil {
+			return err
+		}
+		if err := verifyChecksum(file); err != nil {
+			return err
+		}
+		client, err := connect(dest.Namespace)
+		if err != nil {
+			return err
+		}
+		if err := mysql.Restore(context.Background(), client, dest, conn, file); err != nil {
+			return err
+		}
+		fmt.Print(i18n.T("instance.restored", file, dest))
+		return nil
+	},
+}
+
+var verifyBackupCmd = &cobra.Command{
+	Use:   "verify-backup",
+	Short: "Restore a dump into a throwaway MySQL instance and run sanity checks",
+	Long: `Restore a dump into a throwaway MySQL instance and run sanity checks.
+
+A temporary pod is created in the scratch namespace (the namespace is created and
+removed when it does not exist), the dump is restored, the tables are counted and
+row counts and CHECKSUM TABLE are taken on a sample of tables. The pod is deleted
+afterwards unless --keep is set. The result is recorded on the dump's entry in the
+backup catalog; dumps that are not in the catalog only produce a warning.
+
+With --docker-container the dump is restored into a local MySQL container instead,
+which needs no cluster access.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if restoreFile == "" {
+			return apperr.ValidationError(i18n.Errorf("instance.flag_required", "file"))
+		}
+		if verifyOpts.Samples < 1 {
+			return apperr.ValidationError(i18n.Errorf("mysql.invalid_samples", verifyOpts.Samples))
+		}
+		dump, err := mysql.ReadDump(restoreFile)
+		if err != nil {
+			return apperr.ValidationError(err)
+		}
+		if err := verifyChecksum(restoreFile); err != nil {
+			return err
+		}
+		ctx := context.Background()
+		var result *mysql.VerifyResult
+		var verifyErr error
+		if dockerContainer != "" {
+			ex := mysql.DockerExecutor{Container: dockerContainer, Conn: conn}
+			result, verifyErr = mysql.Verify(ctx, ex, conn.User, dump, verifyOpts.Samples, verifyOpts.Timeout)
+		} else {
+			client, err := connectSandbox(verifyOpts.Namespace)
+			if err != nil {
+				return err
+			}
+			result, verifyErr = mysql.VerifyInCluster(ctx, client, dump, verifyOpts, os.Stderr)
+		}
+		if err := mysql.RecordVerification(backupRoot, dump, result, verifyErr); err != nil {
+			fmt.Fprint(os.Stderr, i18n.T("mysql.verify_record_failed", err))
+		}
+		if verifyErr != nil {
+			return i18n.Errorf("mysql.verify_failed", restoreFile, verifyErr)
+		}
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, i18n.Header("TABLE", "ROWS", "CHECKSUM"))
+		for _, s := range result.Samples {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", s.Name, s.Rows, s.Checksum)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Print(i18n.T("mysql.verify_passed", restoreFile, result.Tables, result.Duration.Round(time.Second)))
+		return nil
+	},
+}
+
+// resolveDump 返回要恢复的备份文件，未指定 --file 时取源实例中 --database 最新的备份
+func resolveDump() (string, error) {
+	if restoreFile != "" {
+		if src.Name == "" {
+			return restoreFile, nil
+		}
+		dump, err := mysql.ReadDump(restoreFile)
+		if err != nil {
+			return "", apperr.ValidationError(err)
+		}
+		if dump.Instance != src {
+			return "", apperr.ValidationError(i18n.Errorf("mysql.src_mismatch", restoreFile, dump.Instance, src))
+		}
+		return restoreFile, nil
+	}
+	if err := requireInstance(src, "src-namespace", "src-name"); err != nil {
+		return "", err
+	}
+	if restoreDatabase == "" {
+		return "", apperr.ValidationError(i18n.Errorf("mysql.database_required"))
+	}
+	dumps, err := mysql.ListBackups(backupRoot, src)
+	if err != nil {
+		return "", err
+	}
+	dump, ok := mysql.Latest(dumps, restoreDatabase)
+	if !ok {
+		return "", apperr.ValidationError(i18n.Errorf("mysql.no_backup", restoreDatabase, src, backupRoot))
+	}
+	return dump.Path, nil
+}
+
+// verifyChecksum 备份目录索引中记录了校验和时，恢复前确认备份文件没有损坏
+func verifyChecksum(file string) error {
+	c, err := catalog.Load(backupRoot)
+	if err != nil {
+		return err
+	}
+	entry, ok := c.Find(file)
+	if !ok || entry.Checksum == "" {
+		return nil
+	}
+	sum, err := catalog.FileChecksum(file)
+	if err != nil {
+		return err
+	}
+	if sum != entry.Checksum {
+		return apperr.ValidationError(i18n.Errorf("catalog.checksum_mismatch", file, entry.Checksum, sum))
+	}
+	return nil
+}
+
+func requireInstance(i mysql.Instance, namespaceFlag, nameFlag string) error {
+	if i.Namespace == "" {
+		return apperr.ValidationError(i18n.Errorf("instance.flag_required", namespaceFlag))
+	}
+	if i.Name == "" {
+		return apperr.ValidationError(i18n.Errorf("instance.flag_required", nameFlag))
+	}
+	return nil
+}
+
+// connect 创建 clientset 并预检查找 Pod 和 exec 所需的权限，--skip-preflight 时跳过预检
+func connect(namespace string) (*kubernetes.Clientset, error) {
+	client, err := api.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	if skipPreflight {
+		return client, nil
+	}
+	perms := []preflight.Permission{
+		{Group: "apps", Resource: "statefulsets", Verb: "get", Namespace: namespace},
+		{Resource: "pods", Verb: "get", Namespace: namespace},
+		{Resource: "pods", Verb: "list", Namespace: namespace},
+		{Resource: "pods", Subresource: "exec", Verb: "create", Namespace: namespace},
+	}
+	if err := preflight.Require(context.Background(), client, perms, os.Stderr); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// connectSandbox 创建 clientset 并预检在临时命名空间中创建实例和 exec 所需的权限
+func connectSandbox(namespace string) (*kubernetes.Clientset, error) {
+	client, err := api.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	if skipPreflight {
+		return client, nil
+	}
+	perms := []preflight.Permission{
+		{Resource: "namespaces", Verb: "get", Namespace: namespace},
+		{Resource: "namespaces", Verb: "create"},
+		{Resource: "namespaces", Verb: "delete", Namespace: namespace},
+		{Resource: "pods", Verb: "create", Namespace: namespace},
+		{Resource: "pods", Verb: "get", Namespace: namespace},
+		{Resource: "pods", Verb: "delete", Namespace: namespace},
+		{Resource: "pods", Subresource: "exec", Verb: "create", Namespace: namespace},
+	}
+	if err := preflight.Require(context.Background(), client, perms, os.Stderr); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func printDumps(dumps []mysql.Dump) error {
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, i18n.Header("TIME", "INSTANCE", "DATABASE", "SIZE", "FILE"))
+	for _, d := range dumps {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.Time.Format("2006-01-02 15:04:05"), d.Instance, d.Database, mysql.FormatSize(d.Size), d.Path)
+	}
+	return w.Flush()
+}
+
+func MysqlCmd() *cobra.Command {
+	return mysqlCmd
+}
+func init() {
+	// 不使用 --backup-dir，避免与配置文件中 clean-storage 的备份目录混用
+	mysqlCmd.PersistentFlags().StringVar(&backupRoot, "backup-root", "/backup", "root directory of the dump files")
+	mysqlCmd.PersistentFlags().StringVar(&conn.Container, "container", "", "container running mysql (default the first container of the pod)")
+	mysqlCmd.PersistentFlags().StringVar(&conn.User, "user", "root", "MySQL user")
+	mysqlCmd.PersistentFlags().StringVar(&conn.PasswordEnv, "password-env", "MYSQL_ROOT_PASSWORD", "environment variable in the container holding the password")
+	mysqlCmd.PersistentFlags().BoolVar(&skipPreflight, "skip-preflight", false, "skip the RBAC permission preflight check")
+	mysqlCmd.AddCommand(backupCmd)
+	backupCmd.Flags().StringVarP(&inst.Namespace, "namespace", "n", "", "namespace of the instance")
+	backupCmd.Flags().StringVar(&inst.Name, "name", "", "StatefulSet or pod name of the instance")
+	backupCmd.Flags().StringSliceVar(&databases, "database", []string{"all"}, "databases to back up, all for every non-system database")
+	_ = backupCmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	mysqlCmd.AddCommand(listBackupCmd)
+	listBackupCmd.Flags().StringVarP(&inst.Namespace, "namespace", "n", "", "namespace of the instance")
+	listBackupCmd.Flags().StringVar(&inst.Name, "name", "", "only list backups of this instance")
+	_ = listBackupCmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	mysqlCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().StringVar(&src.Namespace, "src-namespace", "", "namespace of the instance the dump was taken from")
+	restoreCmd.Flags().StringVar(&src.Name, "src-name", "", "name of the instance the dump was taken from")
+	restoreCmd.Flags().StringVar(&dest.Namespace, "dest-namespace", "", "namespace of the instance to restore into (default --src-namespace)")
+	restoreCmd.Flags().StringVar(&dest.Name, "dest-name", "", "name of the instance to restore into")
+	restoreCmd.Flags().StringVar(&restoreFile, "file", "", "dump file to restore")
+	restoreCmd.Flags().StringVar(&restoreDatabase, "database", "", "database whose latest backup is restored when --file is not set")
+	_ = restoreCmd.RegisterFlagCompletionFunc("src-namespace", completion.Namespaces)
+	_ = restoreCmd.RegisterFlagCompletionFunc("dest-namespace", completion.Namespaces)
+	mysqlCmd.AddCommand(verifyBackupCmd)
+	verifyBackupCmd.Flags().StringVar(&restoreFile, "file", "", "dump file to verify")
+	verifyBackupCmd.Flags().StringVar(&verifyOpts.Namespace, "scratch-namespace", "devops-tool-verify", "namespace of the temporary instance")
+	verifyBackupCmd.Flags().StringVar(&verifyOpts.Image, "image", "mysql:8.0", "image of the temporary instance")
+	verifyBackupCmd.Flags().IntVar(&verifyOpts.Samples, "sample-tables", 5, "number of tables to count rows and CHECKSUM TABLE on")
+	verifyBackupCmd.Flags().DurationVar(&verifyOpts.Timeout, "timeout", 5*time.Minute, "time to wait for the temporary instance to start")
+	verifyBackupCmd.Flags().BoolVar(&verifyOpts.Keep, "keep", false, "keep the temporary instance for troubleshooting")
+	verifyBackupCmd.Flags().StringVar(&dockerContainer, "docker-container", "", "verify in this local MySQL container through docker exec instead of the cluster")
+	_ = verifyBackupCmd.RegisterFlagCompletionFunc("scratch-namespace", completion.Namespaces)
+}
@@ -0,0 +1,28 @@
+package policyCmd
+
+import (
+	"devops_tools/internal/policy"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "StorageCleanupPolicy commands",
+}
+var crdCmd = &cobra.Command{
+	Use:   "crd",
+	Short: "Print the StorageCleanupPolicy CustomResourceDefinition manifest",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := os.Stdout.Write(policy.CRDManifest())
+		return err
+	},
+}
+
+func PolicyCmd() *cobra.Command {
+	return policyCmd
+}
+func init() {
+	policyCmd.AddCommand(crdCmd)
+}
@@ -0,0 +1,239 @@
+package tidbCmd
+
+import (
+	"context"
+	"devops_tools/internal/api"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/catalog"
+	"devops_tools/internal/completion"
+	"devops_tools/internal/i18n"
+	"devops_tools/internal/mysql"
+	"devops_tools/internal/preflight"
+	"devops_tools/internal/tidb"
+	"fmt"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var tidbCmd = &cobra.Command{
+	Use:   "tidb",
+	Short: "TiDB backup and restore commands built on BR and Dumpling jobs",
+}
+var jobOpts tidb.JobOptions
+var skipPreflight bool
+var inst mysql.Instance
+var tool string
+var databases []string
+var storage string
+var catalogDir string
+var src, dest mysql.Instance
+var restoreFile string
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Run a BR or Dumpling job against the cluster and record the backup metadata",
+	Long: `Run a BR or Dumpling job in the namespace of the TidbCluster and record the backup metadata
+(databases, TSO, size, path) in a ConfigMap named after the job.
+
+Dumpling writes to --pvc mounted at --backup-root. BR writes to --storage, or to
+local://<backup-root>/... when --storage is not set, which requires the volume to be shared with TiKV.
+
+With --catalog the backup is also recorded in catalog.json under that directory, which must be
+where the backup PVC is mounted on this machine, so that backup prune can expire it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := tidb.ParseTool(tool)
+		if err != nil {
+			return err
+		}
+		if err := requireInstance(inst, "namespace", "name"); err != nil {
+			return err
+		}
+		ctx := context.Background()
+		client, dyn, err := connect(inst.Namespace)
+		if err != nil {
+			return err
+		}
+		c, err := tidb.Discover(ctx, client, dyn, inst)
+		if err != nil {
+			return err
+		}
+		if catalogDir != "" {
+			if info, err := os.Stat(catalogDir); err != nil || !info.IsDir() {
+				return apperr.ValidationError(i18n.Errorf("tidb.catalog_not_dir", catalogDir))
+			}
+		}
+		rec, err := tidb.Backup(ctx, client, c, tidb.BackupOptions{JobOptions: jobOpts, Tool: t, Databases: databases, Storage: storage}, os.Stderr)
+		if err != nil {
+			return err
+		}
+		// 显式指定本机挂载的备份目录时，同时记录到备份目录索引，供 backup prune 使用
+		if catalogDir != "" {
+			if err := catalog.Record(catalogDir, rec.Entry(jobOpts.Root, catalogDir)); err != nil {
+				return err
+			}
+		}
+		return printRecords([]tidb.Record{*rec})
+	},
+}
+var listBackupCmd = &cobra.Command{
+	Use:   "list-backup",
+	Short: "List recorded backups of a TiDB cluster",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if inst.Namespace == "" {
+			return apperr.ValidationError(i18n.Errorf("instance.flag_required", "namespace"))
+		}
+		client, err := api.NewClient()
+		if err != nil {
+			return err
+		}
+		records, err := tidb.ListBackups(context.Background(), client, inst.Namespace, inst.Name)
+		if err != nil {
+			return err
+		}
+		return printRecords(records)
+	},
+}
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a backup into a TiDB cluster, possibly in another namespace",
+	Long: `Restore a backup into a TiDB cluster, possibly in another namespace.
+
+The restore job runs in --src-namespace so that it can mount the backup PVC, and reaches the
+destination cluster through its Service DNS name. Without --file the latest backup of the source
+cluster is restored. BR backups are restored with br, Dumpling backups with tidb-lightning.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireInstance(src, "src-namespace", "src-name"); err != nil {
+			return err
+		}
+		if dest.Namespace == "" {
+			dest.Namespace = src.Namespace
+		}
+		if err := requireInstance(dest, "dest-namespace", "dest-name"); err != nil {
+			return err
+		}
+		ctx := context.Background()
+		client, dyn, err := connect(src.Namespace)
+		if err != nil {
+			return err
+		}
+		records, err := tidb.ListBackups(ctx, client, src.Namespace, src.Name)
+		if err != nil {
+			return err
+		}
+		var rec *tidb.Record
+		var ok bool
+		if restoreFile == "" {
+			if ok = len(records) > 0; ok {
+				rec = &records[0]
+			}
+		} else {
+			rec, ok = tidb.FindBackup(records, restoreFile)
+		}
+		if !ok {
+			return apperr.ValidationError(i18n.Errorf("tidb.no_backup", src, restoreFile))
+		}
+		c, err := tidb.Discover(ctx, client, dyn, dest)
+		if err != nil {
+			return err
+		}
+		if err := tidb.Restore(ctx, client, c, rec, jobOpts, os.Stderr); err != nil {
+			return err
+		}
+		fmt.Print(i18n.T("instance.restored", rec.Path, dest))
+		return nil
+	},
+}
+
+func requireInstance(i mysql.Instance, namespaceFlag, nameFlag string) error {
+	if i.Namespace == "" {
+		return apperr.ValidationError(i18n.Errorf("instance.flag_required", namespaceFlag))
+	}
+	if i.Name == "" {
+		return apperr.ValidationError(i18n.Errorf("instance.flag_required", nameFlag))
+	}
+	return nil
+}
+
+// connect 创建 clientset 和 dynamic client，并预检 Job、日志和备份记录所需的权限
+func connect(namespace string) (kubernetes.Interface, dynamic.Interface, error) {
+	client, err := api.NewClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	dyn, err := api.NewDynamicClient()
+	if err != nil {
+		return nil, nil, apperr.ConnectionError(err)
+	}
+	if skipPreflight {
+		return client, dyn, nil
+	}
+	perms := []preflight.Permission{
+		{Resource: "services", Verb: "get", Namespace: namespace},
+		{Group: "batch", Resource: "jobs", Verb: "create", Namespace: namespace},
+		{Group: "batch", Resource: "jobs", Verb: "get", Namespace: namespace},
+		{Resource: "pods", Verb: "list", Namespace: namespace},
+		{Resource: "pods", Subresource: "log", Verb: "get", Namespace: namespace},
+		{Resource: "configmaps", Verb: "create", Namespace: namespace},
+		{Resource: "configmaps", Verb: "list", Namespace: namespace},
+	}
+	if err := preflight.Require(context.Background(), client, perms, os.Stderr); err != nil {
+		return nil, nil, err
+	}
+	return client, dyn, nil
+}
+
+func printRecords(records []tidb.Record) error {
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, i18n.Header("TIME", "INSTANCE", "TOOL", "DATABASE", "TSO", "SIZE", "PATH"))
+	for _, r := range records {
+		dbs := "all"
+		if len(r.Databases) > 0 {
+			dbs = strings.Join(r.Databases, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s/%s\t%s\t%s\t%d\t%s\t%s\n", r.Time.Format("2006-01-02 15:04:05"), r.Namespace, r.Instance, r.Tool, dbs, r.TSO, mysql.FormatSize(r.Size), r.Path)
+	}
+	return w.Flush()
+}
+
+func TidbCmd() *cobra.Command {
+	return tidbCmd
+}
+func init() {
+	tidbCmd.PersistentFlags().StringVar(&jobOpts.Root, "backup-root", "/backup", "mount path of the backup PVC in the job")
+	tidbCmd.PersistentFlags().StringVar(&jobOpts.PVC, "pvc", "", "backup PVC mounted at --backup-root, in the namespace the job runs in")
+	tidbCmd.PersistentFlags().StringVar(&jobOpts.Image, "image", "", "job image (default pingcap/<tool> with the TidbCluster version)")
+	tidbCmd.PersistentFlags().StringVar(&jobOpts.User, "user", "root", "TiDB user for Dumpling and tidb-lightning")
+	tidbCmd.PersistentFlags().StringVar(&jobOpts.PasswordSecret, "password-secret", "", "<secret>/<key> holding the TiDB password, in the namespace the job runs in")
+	tidbCmd.PersistentFlags().DurationVar(&jobOpts.Timeout, "timeout", 6*time.Hour, "how long to wait for the job to finish")
+	tidbCmd.PersistentFlags().BoolVar(&skipPreflight, "skip-preflight", false, "skip the RBAC permission preflight check")
+	tidbCmd.AddCommand(backupCmd)
+	backupCmd.Flags().StringVarP(&inst.Namespace, "namespace", "n", "", "namespace of the TidbCluster")
+	backupCmd.Flags().StringVar(&inst.Name, "name", "", "name of the TidbCluster")
+	backupCmd.Flags().StringVar(&tool, "tool", string(tidb.ToolDumpling), "backup tool: dumpling or br")
+	backupCmd.Flags().StringSliceVar(&databases, "database", []string{"all"}, "databases to back up, all for every non-system database")
+	backupCmd.Flags().StringVar(&storage, "storage", "", "BR storage URL, e.g. s3://bucket/prefix")
+	backupCmd.Flags().StringVar(&catalogDir, "catalog", "", "local mount directory of the backup PVC to record the backup in its catalog.json")
+	_ = backupCmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	_ = backupCmd.RegisterFlagCompletionFunc("tool", completion.Fixed(string(tidb.ToolDumpling), string(tidb.ToolBR)))
+	tidbCmd.AddCommand(listBackupCmd)
+	listBackupCmd.Flags().StringVarP(&inst.Namespace, "namespace", "n", "", "namespace of the TidbCluster")
+	listBackupCmd.Flags().StringVar(&inst.Name, "name", "", "only list backups of this TidbCluster")
+	_ = listBackupCmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	tidbCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().StringVar(&src.Namespace, "src-namespace", "", "namespace of the backed-up TidbCluster and the backup PVC")
+	restoreCmd.Flags().StringVar(&src.Name, "src-name", "", "name of the backed-up TidbCluster")
+	restoreCmd.Flags().StringVar(&dest.Namespace, "dest-namespace", "", "namespace of the TidbCluster to restore into (default --src-namespace)")
+	restoreCmd.Flags().StringVar(&dest.Name, "dest-name", "", "name of the TidbCluster to restore into")
+	restoreCmd.Flags().StringVar(&restoreFile, "file", "", "backup path or BR storage URL as shown by list-backup (default the latest backup)")
+	_ = restoreCmd.RegisterFlagCompletionFunc("src-namespace", completion.Namespaces)
+	_ = restoreCmd.RegisterFlagCompletionFunc("dest-namespace", completion.Namespaces)
+}
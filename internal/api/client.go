@@ -2,33 +2,117 @@ package api
 
 import (
 	"context"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"log"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+	"sort"
 	"time"
 )
 
-func NewClient() (*kubernetes.Clientset, error) {
+// 客户端侧限流参数，由命令行 --qps/--burst 覆盖
+var (
+	QPS   float32 = 20
+	Burst int     = 40
+)
+
+// Kubeconfig 和 Context 由 --kubeconfig/--context 或配置文件设置，为空时使用 KUBECONFIG 或 ~/.kube/config 及其当前 context
+var (
+	Kubeconfig string
+	Context    string
+)
+
+// RestConfig 优先使用 kubeconfig，不存在时使用集群内 ServiceAccount 凭证。
+// 指定了 --cluster-sign 时不回退到集群内凭证，避免连接到错误的集群
+func RestConfig() (*rest.Config, error) {
 	//configpath := "C:\\Users\\侯哥哥\\.kube\\config"
-	config, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
-	//confignew, err := clientcmd.BuildConfigFromFlags("", configpath)
-	//if err != nil {
-	//	inClusterConfig, err := rest.InClusterConfig()
-	//	if err != nil {
-	//		log.Fatalln("can't find config")
-	//	}
-	//	confignew = inClusterConfig
-	//}
+	kubeconfig, kubeContext, server, err := target()
+	if err != nil {
+		return nil, err
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	overrides.ClusterInfo.Server = server
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil && ClusterSign != "" {
+		return nil, apperr.ConnectionError(i18n.Errorf("api.cluster_sign_failed", ClusterSign, err))
+	}
+	if err != nil {
+		inClusterConfig, inClusterErr := rest.InClusterConfig()
+		if inClusterErr != nil {
+			return nil, apperr.ConnectionError(i18n.Errorf("api.no_config", err, inClusterErr))
+		}
+		config = inClusterConfig
+	}
+	config.QPS = QPS
+	config.Burst = Burst
+	return config, nil
+}
+
+// Contexts 返回 kubeconfig 中的全部 context 名称
+func Contexts() ([]string, error) {
+	kubeconfig, _, _, err := target()
+	if err != nil {
+		return nil, err
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	raw, err := rules.Load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(raw.Contexts))
+	for name := range raw.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// NewClient 创建 clientset 并通过 list namespaces 检查连通性，
+// 认证或授权失败返回 Permission 类别错误，其余返回 Connection 类别错误
+func NewClient() (*kubernetes.Clientset, error) {
+	config, err := RestConfig()
+	if err != nil {
+		return nil, err
+	}
 	//2.creat clientset
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		log.Fatalln("can't create clientset")
+		return nil, apperr.ConnectionError(i18n.Errorf("api.clientset_failed", err))
 	}
-	context.WithTimeout(context.Background(), 10*time.Second)
-	_, err = clientset.CoreV1().Namespaces().List(context.Background(), metaV1.ListOptions{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = clientset.CoreV1().Namespaces().List(ctx, metaV1.ListOptions{Limit: 1})
 	if err != nil {
-		log.Fatalln("can't create clientset")
+		if apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err) {
+			return nil, apperr.PermissionError(i18n.Errorf("api.list_ns_failed", err))
+		}
+		return nil, apperr.ConnectionError(i18n.Errorf("api.connect_failed", err))
 	}
 	return clientset, nil
 }
+
+// NewDynamicClient 创建用于访问 CRD 等非内置资源的 dynamic client
+func NewDynamicClient() (dynamic.Interface, error) {
+	config, err := RestConfig()
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(config)
+}
+
+// NewMetricsClient 创建访问 metrics.k8s.io 的 clientset，需要集群中部署 metrics-server
+func NewMetricsClient() (metricsclient.Interface, error) {
+	config, err := RestConfig()
+	if err != nil {
+		return nil, err
+	}
+	return metricsclient.NewForConfig(config)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Cluster 集群登记表中的一个集群，通过 --cluster-sign 以逻辑名称选择
+type Cluster struct {
+	// Kubeconfig 为空时使用 KUBECONFIG 或 ~/.kube/config，支持 ~/ 开头的路径
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Context 为空时使用 kubeconfig 的当前 context
+	Context string `json:"context,omitempty"`
+	// Server 非空时覆盖 kubeconfig 中的 API Server 地址，例如通过 VIP 或跳板地址访问
+	Server      string            `json:"server,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// Clusters 集群登记表，由配置文件中的 clusters 设置
+var Clusters map[string]Cluster
+
+// ClusterSign 由 --cluster-sign 或配置文件中的 clusterSign 设置。
+// 非空时连接参数完全由登记表中的集群决定，--kubeconfig 和 --context 不再生效
+var ClusterSign string
+
+// ClusterSigns 返回排序后的全部集群标识
+func ClusterSigns() []string {
+	signs := make([]string, 0, len(Clusters))
+	for sign := range Clusters {
+		signs = append(signs, sign)
+	}
+	sort.Strings(signs)
+	return signs
+}
+
+// target 返回本次连接使用的 kubeconfig、context 和 API Server 覆盖地址
+func target() (kubeconfig, context, server string, err error) {
+	if ClusterSign == "" {
+		return Kubeconfig, Context, "", nil
+	}
+	c, ok := Clusters[ClusterSign]
+	if !ok {
+		return "", "", "", apperr.ValidationError(i18n.Errorf("api.unknown_cluster_sign", ClusterSign, strings.Join(ClusterSigns(), ", ")))
+	}
+	return expandHome(c.Kubeconfig), c.Context, c.Server, nil
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"devops_tools/internal/apperr"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: a
+  cluster: {server: "https://a:6443"}
+- name: b
+  cluster: {server: "https://b:6443"}
+users:
+- name: u
+  user: {token: t}
+contexts:
+- name: a
+  context: {cluster: a, user: u}
+- name: b
+  context: {cluster: b, user: u}
+current-context: a
+`
+
+func TestClusterSign(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	Clusters = map[string]Cluster{
+		"standard": {Kubeconfig: path, Context: "b"},
+		"dr":       {Kubeconfig: path, Server: "https://vip:6443"},
+	}
+	defer func() { Clusters, ClusterSign, Kubeconfig = nil, "", "" }()
+	// --kubeconfig 不影响集群标识选择的集群
+	Kubeconfig = filepath.Join(t.TempDir(), "missing")
+
+	for sign, want := range map[string]string{"standard": "https://b:6443", "dr": "https://vip:6443"} {
+		ClusterSign = sign
+		config, err := RestConfig()
+		if err != nil {
+			t.Fatalf("RestConfig(%s) error = %v", sign, err)
+		}
+		if config.Host != want {
+			t.Errorf("RestConfig(%s).Host = %s, want %s", sign, config.Host, want)
+		}
+	}
+
+	ClusterSign = "unknown"
+	if _, err := RestConfig(); apperr.CategoryOf(err) != apperr.Validation {
+		t.Errorf("RestConfig(unknown) error = %v, want validation error", err)
+	}
+}
@@ -0,0 +1,103 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Category 错误类别，决定进程退出码
+type Category int
+
+const (
+	// General 未分类的错误
+	General Category = iota
+	// Validation 参数或输入不合法
+	Validation
+	// Connection 无法连接集群或加载 kubeconfig
+	Connection
+	// Permission 缺少 RBAC 权限或认证失败
+	Permission
+	// PartialFailure 部分操作失败，例如清理时部分资源删除失败
+	PartialFailure
+)
+
+// ExitCode 类别对应的进程退出码
+func (c Category) ExitCode() int {
+	switch c {
+	case Validation:
+		return 2
+	case Connection:
+		return 3
+	case Permission:
+		return 4
+	case PartialFailure:
+		return 5
+	}
+	return 1
+}
+
+func (c Category) String() string {
+	switch c {
+	case Validation:
+		return "validation"
+	case Connection:
+		return "connection"
+	case Permission:
+		return "permission"
+	case PartialFailure:
+		return "partial failure"
+	}
+	return "general"
+}
+
+// Error 带类别的错误
+type Error struct {
+	Category Category
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func wrap(c Category, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Category: c, Err: err}
+}
+
+func ValidationError(err error) error     { return wrap(Validation, err) }
+func ConnectionError(err error) error     { return wrap(Connection, err) }
+func PermissionError(err error) error     { return wrap(Permission, err) }
+func PartialFailureError(err error) error { return wrap(PartialFailure, err) }
+
+// Validationf 以格式化字符串构造参数校验错误
+func Validationf(format string, args ...interface{}) error {
+	return ValidationError(fmt.Errorf(format, args...))
+}
+
+// CategoryOf 返回错误的类别，未显式分类的 403/401 归为 Permission
+func CategoryOf(err error) Category {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Category
+	}
+	if apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err) {
+		return Permission
+	}
+	return General
+}
+
+// ExitCode 返回 err 对应的退出码，err 为 nil 时返回 0
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return CategoryOf(err).ExitCode()
+}
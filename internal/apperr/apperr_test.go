@@ -0,0 +1,29 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("boom"), 1},
+		{Validationf("bad flag"), 2},
+		{ConnectionError(errors.New("dial tcp")), 3},
+		{fmt.Errorf("load: %w", PermissionError(errors.New("denied"))), 4},
+		{apierrors.NewForbidden(schema.GroupResource{Resource: "persistentvolumes"}, "pv-1", errors.New("denied")), 4},
+		{PartialFailureError(errors.New("1 of 2 failed")), 5},
+	}
+	for _, tc := range cases {
+		if got := ExitCode(tc.err); got != tc.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
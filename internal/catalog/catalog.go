@@ -0,0 +1,193 @@
+package catalog
+
+import (
+	"crypto/sha256"
+	"devops_tools/internal/i18n"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FileName 备份根目录下的目录索引文件
+const FileName = "catalog.json"
+
+// Status 备份状态
+type Status string
+
+const (
+	StatusCompleted Status = "Completed"
+	StatusFailed    Status = "Failed"
+)
+
+// Entry 一次备份的记录，Path 在目录中唯一
+type Entry struct {
+	// Engine mysql 或 tidb
+	Engine string `json:"engine"`
+	// Instance namespace/name
+	Instance  string   `json:"instance"`
+	Databases []string `json:"databases,omitempty"`
+	// Path 备份文件或目录，BR 备份为存储地址
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// Checksum sha256:<hex>，目录备份为目录内 sha256sum 按路径排序后输出内容的 sha256
+	Checksum string    `json:"checksum,omitempty"`
+	Status   Status    `json:"status"`
+	Message  string    `json:"message,omitempty"`
+	Time     time.Time `json:"time"`
+	// Verification 最近一次恢复验证的结果，未验证时为空
+	Verification *Verification `json:"verification,omitempty"`
+}
+
+// Verification 将备份恢复到临时实例并检查的结果
+type Verification struct {
+	Time    time.Time `json:"time"`
+	Passed  bool      `json:"passed"`
+	Tables  int       `json:"tables,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Catalog 备份根目录下的 JSON 索引
+type Catalog struct {
+	root    string
+	Entries []Entry `json:"entries"`
+}
+
+// Load 读取 root 下的索引，文件不存在时返回空索引
+func Load(root string) (*Catalog, error) {
+	c := &Catalog{root: root}
+	data, err := os.ReadFile(filepath.Join(root, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, i18n.Errorf("catalog.parse_failed", filepath.Join(root, FileName), err)
+	}
+	return c, nil
+}
+
+// Add 添加记录，已有相同 Path 的记录会被替换。
+// 失败的记录不会替换已完成的记录，否则清理失败备份时会删除仍然有效的备份文件
+func (c *Catalog) Add(entries ...Entry) {
+	for _, e := range entries {
+		if existing, ok := c.Find(e.Path); ok {
+			if e.Status != StatusCompleted && existing.Status == StatusCompleted {
+				continue
+			}
+			*existing = e
+			continue
+		}
+		c.Entries = append(c.Entries, e)
+	}
+}
+
+// Find 按 Path 查找记录
+func (c *Catalog) Find(path string) (*Entry, bool) {
+	for i := range c.Entries {
+		if c.Entries[i].Path == path {
+			return &c.Entries[i], true
+		}
+	}
+	return nil, false
+}
+
+// Remove 删除 Path 对应的记录
+func (c *Catalog) Remove(path string) {
+	kept := c.Entries[:0]
+	for _, e := range c.Entries {
+		if e.Path != path {
+			kept = append(kept, e)
+		}
+	}
+	c.Entries = kept
+}
+
+// Save 按时间倒序写回索引，先写临时文件再重命名，避免中断时留下不完整的索引
+func (c *Catalog) Save() error {
+	sort.SliceStable(c.Entries, func(i, j int) bool {
+		return c.Entries[i].Time.After(c.Entries[j].Time)
+	})
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.root, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(c.root, FileName)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Update 在索引锁内读取、修改并写回索引，多个备份任务可以同时写同一个备份根目录
+func Update(root string, fn func(c *Catalog) error) error {
+	unlock, err := lock(root)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	c, err := Load(root)
+	if err != nil {
+		return err
+	}
+	if err := fn(c); err != nil {
+		return err
+	}
+	return c.Save()
+}
+
+// Record 将记录写入 root 下的索引
+func Record(root string, entries ...Entry) error {
+	return Update(root, func(c *Catalog) error {
+		c.Add(entries...)
+		return nil
+	})
+}
+
+// lock 通过独占创建 catalog.json.lock 加锁，最多等待 30 秒。
+// 进程异常退出留下的锁文件需要手动删除
+func lock(root string) (func(), error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(root, FileName+".lock")
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, i18n.Errorf("catalog.locked", path)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// FileChecksum 计算文件的 sha256，格式为 sha256:<hex>
+func FileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,108 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPolicyExpired(t *testing.T) {
+	// 从 2025-01-01 开始每天一个备份，共 120 天，外加一个失败的备份
+	start := time.Date(2025, 1, 1, 2, 0, 0, 0, time.Local)
+	var entries []Entry
+	for i := 0; i < 120; i++ {
+		entries = append(entries, Entry{Engine: "mysql", Instance: "ns/dol-mysql", Databases: []string{"clog"},
+			Path: start.AddDate(0, 0, i).Format("20060102"), Status: StatusCompleted, Time: start.AddDate(0, 0, i)})
+	}
+	entries = append(entries, Entry{Engine: "mysql", Instance: "ns/dol-mysql", Databases: []string{"clog"},
+		Path: "failed", Status: StatusFailed, Time: start.AddDate(0, 0, 119).Add(time.Hour)})
+	// 其他数据库的备份单独计算保留
+	entries = append(entries, Entry{Engine: "mysql", Instance: "ns/dol-mysql", Databases: []string{"other"},
+		Path: "other", Status: StatusCompleted, Time: start})
+
+	expired := Policy{Daily: 7, Weekly: 4, Monthly: 3}.Expired(entries)
+	kept := len(entries) - len(expired)
+	// 最后一天是周三 04-30：daily 保留 04-24 至 04-30，weekly 另外保留 04-20 和 04-13
+	// （04-27 所在周已由 daily 保留），monthly 另外保留 03-31 和 02-28，以及 other
+	if want := 7 + 2 + 2 + 1; kept != want {
+		t.Errorf("kept %d entries, want %d", kept, want)
+	}
+	for _, e := range expired {
+		if e.Path == "other" || e.Path == start.AddDate(0, 0, 119).Format("20060102") {
+			t.Errorf("entry %s should be kept", e.Path)
+		}
+	}
+	if expired[len(expired)-1].Path != "failed" {
+		t.Errorf("failed backup should expire, last expired = %s", expired[len(expired)-1].Path)
+	}
+	if err := (Policy{}).Validate(); err == nil {
+		t.Error("empty policy passed validation")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	var entries []Entry
+	for i, day := range []string{"20250101", "20250102"} {
+		path := filepath.Join(root, day, "clog", day+"_clog.sql.gz")
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("dump"), 0644)
+		entries = append(entries, Entry{Engine: "mysql", Instance: "ns/dol-mysql", Databases: []string{"clog"},
+			Path: path, Status: StatusCompleted, Time: now.AddDate(0, 0, i-1)})
+	}
+	if err := Record(root, entries...); err != nil {
+		t.Fatal(err)
+	}
+	expired, err := Prune(root, Policy{Daily: 1}, false)
+	if err != nil || len(expired) != 1 || expired[0].Path != entries[0].Path {
+		t.Fatalf("Prune() = %+v, %v", expired, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "20250101")); !os.IsNotExist(err) {
+		t.Errorf("empty date directory was not removed: %v", err)
+	}
+	c, err := Load(root)
+	if err != nil || len(c.Entries) != 1 || c.Entries[0].Path != entries[1].Path {
+		t.Errorf("catalog after prune = %+v, %v", c, err)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	c := &Catalog{}
+	now := time.Now()
+	c.Add(Entry{Path: "a", Status: StatusFailed, Message: "first", Time: now})
+	c.Add(Entry{Path: "a", Status: StatusCompleted, Size: 1, Time: now})
+	if e, ok := c.Find("a"); !ok || e.Status != StatusCompleted || len(c.Entries) != 1 {
+		t.Fatalf("completed entry should replace failed one: %+v", c.Entries)
+	}
+	// 失败的记录不能覆盖同一路径上已完成的备份，否则 Prune 会删除有效的备份文件
+	c.Add(Entry{Path: "a", Status: StatusFailed, Message: "exists", Time: now})
+	if e, _ := c.Find("a"); e.Status != StatusCompleted || e.Size != 1 {
+		t.Errorf("failed entry replaced completed one: %+v", e)
+	}
+	c.Add(Entry{Path: "a", Status: StatusCompleted, Size: 2, Time: now})
+	if e, _ := c.Find("a"); e.Size != 2 || len(c.Entries) != 1 {
+		t.Errorf("completed entry was not replaced: %+v", c.Entries)
+	}
+}
+
+func TestPruneOutsideRoot(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	path := filepath.Join(outside, "clog.sql.gz")
+	os.WriteFile(path, []byte("dump"), 0644)
+	// 失败的记录总是过期，路径不在备份根目录下时不能删除
+	entry := Entry{Engine: "mysql", Instance: "ns/dol-mysql", Path: path, Status: StatusFailed, Time: time.Now()}
+	if err := Record(root, entry); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Prune(root, Policy{Daily: 1}, false); err == nil {
+		t.Error("Prune() should report the entry outside the backup root")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("file outside the backup root was deleted: %v", err)
+	}
+	if c, _ := Load(root); len(c.Entries) != 1 {
+		t.Errorf("entry outside the backup root was removed from the catalog: %+v", c.Entries)
+	}
+}
@@ -0,0 +1,135 @@
+package catalog
+
+import (
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Policy GFS 保留策略：分别保留最近 Daily 天、Weekly 周、Monthly 月中每个周期最新的一个成功备份
+type Policy struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+}
+
+// Validate 至少需要保留一种周期，避免误删全部备份
+func (p Policy) Validate() error {
+	if p.Daily < 0 || p.Weekly < 0 || p.Monthly < 0 {
+		return apperr.ValidationError(i18n.Errorf("catalog.policy_negative"))
+	}
+	if p.Daily+p.Weekly+p.Monthly == 0 {
+		return apperr.ValidationError(i18n.Errorf("catalog.policy_empty"))
+	}
+	return nil
+}
+
+// Expired 返回按策略应删除的记录。保留按实例和数据库分组计算，失败的备份总是过期
+func (p Policy) Expired(entries []Entry) []Entry {
+	groups := map[string][]Entry{}
+	for _, e := range entries {
+		key := e.Engine + "|" + e.Instance + "|" + strings.Join(e.Databases, ",")
+		groups[key] = append(groups[key], e)
+	}
+	var expired []Entry
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Time.After(group[j].Time)
+		})
+		daily, weekly, monthly := map[string]bool{}, map[string]bool{}, map[string]bool{}
+		for _, e := range group {
+			if e.Status != StatusCompleted {
+				expired = append(expired, e)
+				continue
+			}
+			t := e.Time.Local()
+			year, week := t.ISOWeek()
+			keep := false
+			keep = bucket(daily, t.Format("2006-01-02"), p.Daily) || keep
+			keep = bucket(weekly, fmt.Sprintf("%d-W%02d", year, week), p.Weekly) || keep
+			keep = bucket(monthly, t.Format("2006-01"), p.Monthly) || keep
+			if !keep {
+				expired = append(expired, e)
+			}
+		}
+	}
+	sort.SliceStable(expired, func(i, j int) bool {
+		return expired[i].Time.Before(expired[j].Time)
+	})
+	return expired
+}
+
+// bucket 记录是所在周期中最新的一个且周期数未超过 limit 时返回 true
+func bucket(seen map[string]bool, key string, limit int) bool {
+	if seen[key] || len(seen) >= limit {
+		return false
+	}
+	seen[key] = true
+	return true
+}
+
+// Prune 按策略删除 root 下过期的备份文件并更新索引，dryRun 时只返回将要删除的记录。
+// 对象存储上的 BR 备份不会被删除，只从索引中移除
+func Prune(root string, policy Policy, dryRun bool) ([]Entry, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	var expired []Entry
+	var failed []string
+	err := Update(root, func(c *Catalog) error {
+		expired = policy.Expired(c.Entries)
+		if dryRun {
+			return nil
+		}
+		for _, e := range expired {
+			if err := removeBackup(root, e.Path); err != nil {
+				failed = append(failed, i18n.T("catalog.remove_failed", e.Path, err))
+				continue
+			}
+			c.Remove(e.Path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(failed) > 0 {
+		return expired, apperr.PartialFailureError(errors.New(strings.Join(failed, "; ")))
+	}
+	return expired, nil
+}
+
+// removeBackup 删除备份文件或目录，并删除备份根目录下因此变空的日期目录。
+// 不在备份根目录下的路径拒绝删除
+func removeBackup(root, path string) error {
+	if local, ok := strings.CutPrefix(path, "local://"); ok {
+		path = local
+	} else if strings.Contains(path, "://") {
+		return nil
+	}
+	var err error
+	if root, err = filepath.Abs(root); err != nil {
+		return err
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(path, root+string(filepath.Separator)) {
+		return i18n.Errorf("catalog.outside_root", path, root)
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	for dir := filepath.Dir(path); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		// 目录非空时 Remove 失败，停止向上清理
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package cluster
+
+import (
+	"devops_tools/internal/i18n"
+	"fmt"
+	"github.com/tealeg/xlsx/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// CleanupReason 资源被判定为可清理的原因
+type CleanupReason string
+
+const (
+	ReasonUnusedStorageClass CleanupReason = "UnusedStorageClass"
+	ReasonAvailable          CleanupReason = "Available"
+	ReasonReleasedNoClaim    CleanupReason = "ReleasedNoClaim"
+	ReasonPVCMissing         CleanupReason = "PVCMissing"
+	ReasonUIDMismatch        CleanupReason = "UIDMismatch"
+	ReasonNodeMissing        CleanupReason = "NodeMissing"
+)
+
+// CleanupCandidate 清理计划中待删除的一个资源
+type CleanupCandidate struct {
+	Kind    string
+	Name    string
+	Reason  CleanupReason
+	Message string
+	Object  runtime.Object
+}
+
+// CleanupPlan 根据快照计算出的清理计划，不会访问集群
+type CleanupPlan struct {
+	StorageClasses    []CleanupCandidate
+	PersistentVolumes []CleanupCandidate
+	// Skipped 未满足清理条件的 PV 说明
+	Skipped []string
+}
+
+// PlanCleanup 计算未被任何 PV 使用的 StorageClass 以及可回收的 PV
+func PlanCleanup(snap *Snapshot) *CleanupPlan {
+	plan := &CleanupPlan{}
+	for i := range snap.StorageClasses {
+		sc := &snap.StorageClasses[i]
+		if len(snap.PVsByStorageClass(sc.Name)) == 0 {
+			plan.StorageClasses = append(plan.StorageClasses, CleanupCandidate{
+				Kind:    "StorageClass",
+				Name:    sc.Name,
+				Reason:  ReasonUnusedStorageClass,
+				Message: i18n.T("plan.sc_unused", sc.Name),
+				Object:  sc,
+			})
+		}
+	}
+	for i := range snap.PersistentVolumes {
+		pv := &snap.PersistentVolumes[i]
+		reason, msg, deletable := pvCleanupReason(pv, snap)
+		if !deletable {
+			plan.Skipped = append(plan.Skipped, msg)
+			continue
+		}
+		plan.PersistentVolumes = append(plan.PersistentVolumes, CleanupCandidate{
+			Kind:    "PV",
+			Name:    pv.Name,
+			Reason:  reason,
+			Message: msg,
+			Object:  pv,
+		})
+	}
+	return plan
+}
+
+// OrphanCandidates 返回疑似孤儿的 PV：清理计划中的 PV，以及绑定节点已不存在的 local PV。
+// NodeMissing 默认只用于报表和告警，只有在清理原因中显式指定时才会被删除，见 CleanupCandidates
+func (s *Snapshot) OrphanCandidates() []CleanupCandidate {
+	candidates := PlanCleanup(s).PersistentVolumes
+	planned := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		planned[c.Name] = true
+	}
+	for i := range s.PersistentVolumes {
+		pv := &s.PersistentVolumes[i]
+		if planned[pv.Name] || pv.Spec.Local == nil {
+			continue
+		}
+		nodes := localPVNodes(pv)
+		if len(nodes) == 0 {
+			continue
+		}
+		missing := true
+		for _, node := range nodes {
+			if s.NodeExists(node) {
+				missing = false
+			}
+		}
+		if missing {
+			candidates = append(candidates, CleanupCandidate{
+				Kind:    "PV",
+				Name:    pv.Name,
+				Reason:  ReasonNodeMissing,
+				Message: i18n.T("plan.node_missing", pv.Name, strings.Join(nodes, ",")),
+				Object:  pv,
+			})
+		}
+	}
+	return candidates
+}
+
+// CleanupCandidates 返回清理计划中原因属于 reasons 的资源，reasons 为空时返回清理计划中的全部资源。
+// 绑定节点已不存在的 local PV（NodeMissing）不在清理计划中，只有 reasons 显式包含 NodeMissing 时才会加入
+func (s *Snapshot) CleanupCandidates(reasons []CleanupReason) []CleanupCandidate {
+	plan := PlanCleanup(s)
+	pvs := plan.PersistentVolumes
+	for _, r := range reasons {
+		if r == ReasonNodeMissing {
+			pvs = s.OrphanCandidates()
+			break
+		}
+	}
+	return filterReasons(append(plan.StorageClasses, pvs...), reasons)
+}
+
+// pvCleanupReason 判断 PV 是否可以清理，返回原因、日志描述和是否删除
+func pvCleanupReason(pv *corev1.PersistentVolume, snap *Snapshot) (CleanupReason, string, bool) {
+	switch pv.Status.Phase {
+	case "Available":
+		return ReasonAvailable, i18n.T("plan.pv_available", pv.Name), true
+	case "Released":
+		ref := pv.Spec.ClaimRef
+		if ref == nil {
+			return ReasonReleasedNoClaim, i18n.T("plan.pv_released_no_ref", pv.Name), true
+		}
+		pvcInfo, ok := snap.PVC(ref.Namespace, ref.Name)
+		if !ok {
+			return ReasonPVCMissing, i18n.T("plan.pvc_missing", ref.Namespace, ref.Name, pv.Name), true
+		}
+		if ref.UID != "" && ref.UID != pvcInfo.UID {
+			return ReasonUIDMismatch, i18n.T("plan.uid_mismatch", ref.Namespace, ref.Name, pv.Name), true
+		}
+		return "", i18n.T("plan.pv_in_use", pv.Name), false
+	default:
+		return "", i18n.T("plan.pv_skip_phase", pv.Name, pv.Status.Phase), false
+	}
+}
+
+// PrintCleanupPlan 以表格或 Excel 输出清理计划
+func PrintCleanupPlan(plan *CleanupPlan, filePath string) error {
+	candidates := append(append([]CleanupCandidate{}, plan.StorageClasses...), plan.PersistentVolumes...)
+	if filePath == "" {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, i18n.Header("KIND", "NAME", "REASON"))
+		for _, c := range candidates {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", c.Kind, c.Name, c.Reason)
+		}
+		return w.Flush()
+	}
+
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("CleanupPlan")
+	if err != nil {
+		return err
+	}
+	row := sheet.AddRow()
+	row.WriteSlice([]interface{}{"KIND", "NAME", "REASON"}, -1)
+	for _, c := range candidates {
+		row := sheet.AddRow()
+		row.WriteSlice([]interface{}{c.Kind, c.Name, string(c.Reason)}, -1)
+	}
+	if err := file.Save(filePath); err != nil {
+		return err
+	}
+	fmt.Print(i18n.T("plan.file_written", filePath))
+	return nil
+}
@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestPlanCleanup(t *testing.T) {
+	released := func(name string, ref *corev1.ObjectReference) corev1.PersistentVolume {
+		pv := testPV(name, "Released", "1Gi")
+		pv.Spec.StorageClassName = "local"
+		pv.Spec.ClaimRef = ref
+		return pv
+	}
+	snap := &Snapshot{
+		StorageClasses: []storagev1.StorageClass{
+			{ObjectMeta: metaV1.ObjectMeta{Name: "local"}},
+			{ObjectMeta: metaV1.ObjectMeta{Name: "unused"}},
+		},
+		PersistentVolumeClaims: []corev1.PersistentVolumeClaim{
+			{ObjectMeta: metaV1.ObjectMeta{Name: "data", Namespace: "ns", UID: "uid-1"}},
+		},
+		PersistentVolumes: []corev1.PersistentVolume{
+			testPV("available", "Available", "1Gi"),
+			released("no-claim", nil),
+			released("pvc-missing", &corev1.ObjectReference{Namespace: "ns", Name: "gone", UID: "uid-2"}),
+			released("uid-mismatch", &corev1.ObjectReference{Namespace: "ns", Name: "data", UID: "uid-old"}),
+			released("in-use", &corev1.ObjectReference{Namespace: "ns", Name: "data", UID: "uid-1"}),
+			testPV("bound", "Bound", "1Gi"),
+		},
+	}
+	snap.PersistentVolumes[0].Spec.StorageClassName = "local"
+	snap.buildIndexes()
+
+	plan := PlanCleanup(snap)
+	if len(plan.StorageClasses) != 1 || plan.StorageClasses[0].Name != "unused" || plan.StorageClasses[0].Reason != ReasonUnusedStorageClass {
+		t.Errorf("StorageClasses = %+v", plan.StorageClasses)
+	}
+	want := map[string]CleanupReason{
+		"available":    ReasonAvailable,
+		"no-claim":     ReasonReleasedNoClaim,
+		"pvc-missing":  ReasonPVCMissing,
+		"uid-mismatch": ReasonUIDMismatch,
+	}
+	got := make(map[string]CleanupReason)
+	for _, c := range plan.PersistentVolumes {
+		got[c.Name] = c.Reason
+		if c.Kind != "PV" || c.Object == nil {
+			t.Errorf("candidate %s: Kind = %q, Object = %v", c.Name, c.Kind, c.Object)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("PersistentVolumes = %v, want %v", got, want)
+	}
+	for name, reason := range want {
+		if got[name] != reason {
+			t.Errorf("%s: reason = %q, want %q", name, got[name], reason)
+		}
+	}
+	// 仍被 PVC 引用的 Released PV 以及 Bound PV 不会被清理
+	if len(plan.Skipped) != 2 {
+		t.Errorf("Skipped = %v, want 2 entries", plan.Skipped)
+	}
+}
+
+func TestCleanupCandidates(t *testing.T) {
+	stale := localPV("stale", "gone")
+	snap := &Snapshot{
+		StorageClasses:    []storagev1.StorageClass{{ObjectMeta: metaV1.ObjectMeta{Name: "unused"}}, {ObjectMeta: metaV1.ObjectMeta{Name: "local"}}},
+		PersistentVolumes: []corev1.PersistentVolume{testPV("available", "Available", "1Gi"), stale},
+	}
+	snap.buildIndexes()
+	names := func(candidates []CleanupCandidate) map[string]CleanupReason {
+		got := make(map[string]CleanupReason)
+		for _, c := range candidates {
+			got[c.Name] = c.Reason
+		}
+		return got
+	}
+
+	// 未指定原因时只返回清理计划，NodeMissing 不会被清理
+	if got := names(snap.CleanupCandidates(nil)); len(got) != 2 || got["unused"] != ReasonUnusedStorageClass || got["available"] != ReasonAvailable {
+		t.Errorf("CleanupCandidates(nil) = %v", got)
+	}
+	if got := names(snap.CleanupCandidates([]CleanupReason{ReasonNodeMissing})); len(got) != 1 || got["stale"] != ReasonNodeMissing {
+		t.Errorf("CleanupCandidates(NodeMissing) = %v", got)
+	}
+	if got := names(snap.CleanupCandidates([]CleanupReason{ReasonAvailable, ReasonNodeMissing})); len(got) != 2 || got["stale"] != ReasonNodeMissing {
+		t.Errorf("CleanupCandidates(Available, NodeMissing) = %v", got)
+	}
+}
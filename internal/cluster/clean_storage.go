@@ -2,10 +2,13 @@ package cluster
 
 import (
 	"context"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
 	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	bv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	_ "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -13,152 +16,235 @@ import (
 	_ "k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
-const (
-	BackupScDir = "/data/storage-clean/sc"
-	BackupPvDir = "/data/storage-clean/pv"
-	LogFile     = "/data/storage-clean/clean.log"
-)
-
 var (
-	scheme      = runtime.NewScheme()
-	currentTime = time.Now().Format("2006-01-02-15:04:05")
+	// LogFile 清理日志，测试中可以指向临时目录
+	LogFile = "/data/storage-clean/clean.log"
+	scheme  = runtime.NewScheme()
+	// logMu 保证并发 worker 写日志时不会交错
+	logMu sync.Mutex
 )
 
 func init() {
 	_ = corev1.AddToScheme(scheme)
 	_ = storagev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = bv1.AddToScheme(scheme)
+}
+
+// CleanOptions 清理任务的执行参数
+type CleanOptions struct {
+	// Concurrency 并发执行备份和删除的 worker 数量
+	Concurrency int
+	// Recorder 非空时为每个删除的资源记录 Kubernetes Event
+	Recorder record.EventRecorder
+	// BackupDir 备份根目录，为空时使用 /data/storage-clean
+	BackupDir string
+	// DryRun 只记录将要删除的资源，不做备份和删除
+	DryRun bool
+	// Reasons 非空时 CleanStorageResources 只清理这些原因的资源，NodeMissing 只有在这里指定时才会清理
+	Reasons []CleanupReason
 }
 
-// CleanStorageResources 清理集群中的 StorageClass 和 PV 资源
-func CleanStorageResources(client *kubernetes.Clientset) error {
-	if err := os.MkdirAll("/data/storage-clean", 0755); err != nil {
-		return fmt.Errorf("创建备份目录失败/data/storage-clean: %v", err)
+// CleanupResult 一次清理的执行结果
+type CleanupResult struct {
+	Candidates int
+	Deleted    int
+	Failed     int
+	// Skipped ctx 结束后未执行的删除数量
+	Skipped int
+}
+
+// Err 有删除失败或因 ctx 结束未执行完时返回 PartialFailure 类别错误
+func (r CleanupResult) Err() error {
+	if r.Failed > 0 {
+		return apperr.PartialFailureError(i18n.Errorf("clean.partial", r.Failed, r.Candidates))
+	}
+	if r.Skipped > 0 {
+		return apperr.PartialFailureError(i18n.Errorf("clean.interrupted", r.Skipped, r.Candidates))
 	}
-	logToFile("开始执行存储资源清理任务...\n")
+	return nil
+}
 
-	if err := deleteUnusedStorageClasses(client); err != nil {
-		logToFile("清理 StorageClass 出错: %v\n", err)
-		return err
+// CleanStorageResources 清理集群中的 StorageClass 和 PV 资源，有资源删除失败时返回 PartialFailure 类别错误。
+// ctx 结束后不再开始新的删除，已经开始的删除仍会执行完
+func CleanStorageResources(ctx context.Context, client kubernetes.Interface, snap *Snapshot, opts CleanOptions) error {
+	logDir := filepath.Dir(LogFile)
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return i18n.Errorf("clean.mkdir_failed", logDir, err)
 	}
+	logToFile("%s", i18n.T("clean.start"))
 
-	if err := cleanupPersistentVolumes(client); err != nil {
-		logToFile("清理 PV 出错: %v\n", err)
-		return err
+	plan := PlanCleanup(snap)
+	for _, msg := range plan.Skipped {
+		logToFile("%s", msg)
 	}
+	result := DeleteCandidates(ctx, client, snap.CleanupCandidates(opts.Reasons), opts)
 
-	logToFile("存储资源清理完成。\n")
-	return nil
+	logToFile("%s", i18n.T("clean.done"))
+	return result.Err()
 }
-func deleteUnusedStorageClasses(client *kubernetes.Clientset) error {
-	scList, err := client.StorageV1().StorageClasses().List(context.Background(), metav1.ListOptions{})
-	if err != nil {
-		return err
+
+// filterReasons 只保留 reasons 中的候选，reasons 为空时不过滤
+func filterReasons(candidates []CleanupCandidate, reasons []CleanupReason) []CleanupCandidate {
+	if len(reasons) == 0 {
+		return candidates
+	}
+	allowed := make(map[CleanupReason]bool, len(reasons))
+	for _, r := range reasons {
+		allowed[r] = true
 	}
+	var filtered []CleanupCandidate
+	for _, c := range candidates {
+		if allowed[c.Reason] {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
 
-	pvList, err := client.CoreV1().PersistentVolumes().List(context.Background(), metav1.ListOptions{})
-	if err != nil {
-		return err
+// DeleteCandidates 备份并删除给定的候选资源，StorageClass 先于 PV 处理，ctx 结束后剩余的资源计入 Skipped
+func DeleteCandidates(ctx context.Context, client kubernetes.Interface, candidates []CleanupCandidate, opts CleanOptions) CleanupResult {
+	result := CleanupResult{Candidates: len(candidates)}
+	if opts.DryRun {
+		for _, c := range candidates {
+			logToFile("[dry-run] %s", c.Message)
+		}
+		return result
 	}
 
-	usedSC := make(map[string]bool)
-	for _, pv := range pvList.Items {
-		if pv.Spec.StorageClassName != "" {
-			usedSC[pv.Spec.StorageClassName] = true
+	base := opts.BackupDir
+	if base == "" {
+		base = "/data/storage-clean"
+	}
+	runTime := time.Now().Format("2006-01-02-15:04:05")
+	var scs, pvs []CleanupCandidate
+	for _, c := range candidates {
+		if c.Kind == "StorageClass" {
+			scs = append(scs, c)
+		} else {
+			pvs = append(pvs, c)
 		}
 	}
+	for _, group := range []struct {
+		label      string
+		candidates []CleanupCandidate
+		backupDir  string
+	}{
+		{"StorageClass", scs, filepath.Join(base, "sc") + runTime},
+		{"PV", pvs, filepath.Join(base, "pv") + runTime},
+	} {
+		deleted, failed, skipped := runDeleteTasks(ctx, group.label, deleteTasks(client, group.candidates, group.backupDir, opts.Recorder), opts.Concurrency)
+		result.Deleted += deleted
+		result.Failed += failed
+		result.Skipped += skipped
+	}
+	return result
+}
 
-	for _, sc := range scList.Items {
-		if !usedSC[sc.Name] {
-			logToFile("准备删除未使用的 StorageClass: %s\n", sc.Name)
-			err := backupResource(&sc, BackupScDir+currentTime)
-			if err != nil {
-				logToFile("备份 StorageClass %s 失败: %v\n", sc.Name, err)
+// deleteTasks 将清理计划中的资源转换为删除任务
+func deleteTasks(client kubernetes.Interface, candidates []CleanupCandidate, backupDir string, recorder record.EventRecorder) []deleteTask {
+	var tasks []deleteTask
+	for _, c := range candidates {
+		logToFile("%s", c.Message)
+		name := c.Name
+		t := deleteTask{kind: c.Kind, name: name, reason: c.Reason, obj: c.Object, backupDir: backupDir, recorder: recorder}
+		switch c.Kind {
+		case "StorageClass":
+			t.delete = func(ctx context.Context) error {
+				return client.StorageV1().StorageClasses().Delete(ctx, name, metav1.DeleteOptions{})
 			}
-			if err := client.StorageV1().StorageClasses().Delete(context.Background(), sc.Name, metav1.DeleteOptions{}); err != nil {
-				logToFile("删除 StorageClass %s 失败: %v\n", sc.Name, err)
-				continue
+		case "PV":
+			t.delete = func(ctx context.Context) error {
+				return client.CoreV1().PersistentVolumes().Delete(ctx, name, metav1.DeleteOptions{})
 			}
-			logToFile("成功删除并备份 StorageClass: %s\n", sc.Name)
 		}
+		tasks = append(tasks, t)
 	}
-	return nil
+	return tasks
 }
-func cleanupPersistentVolumes(client *kubernetes.Clientset) error {
-	pvList, err := client.CoreV1().PersistentVolumes().List(context.Background(), metav1.ListOptions{})
-	if err != nil {
+
+// deleteTask 一个待备份并删除的资源
+type deleteTask struct {
+	kind      string
+	name      string
+	reason    CleanupReason
+	obj       runtime.Object
+	backupDir string
+	delete    func(ctx context.Context) error
+	recorder  record.EventRecorder
+}
+
+func (t deleteTask) run(ctx context.Context) error {
+	if err := backupResource(t.obj, t.backupDir); err != nil {
+		logToFile("%s", i18n.T("clean.backup_failed", t.kind, t.name, err))
+	}
+	ctx, cancel := context.WithTimeout(ctx, pageTimeout)
+	defer cancel()
+	if err := t.delete(ctx); err != nil {
+		logToFile("%s", i18n.T("clean.delete_failed", t.kind, t.name, err))
+		if t.recorder != nil {
+			t.recorder.Eventf(t.obj, corev1.EventTypeWarning, "CleanupFailed", "Failed to delete %s %s (%s): %v", t.kind, t.name, t.reason, err)
+		}
 		return err
 	}
+	logToFile("%s", i18n.T("clean.deleted", t.kind, t.name))
+	if t.recorder != nil {
+		t.recorder.Eventf(t.obj, corev1.EventTypeNormal, "CleanedUp", "Deleted %s %s by storage cleanup (%s), backup in %s", t.kind, t.name, t.reason, t.backupDir)
+	}
+	return nil
+}
 
-	for _, pv := range pvList.Items {
-		switch pv.Status.Phase {
-		case "Available":
-			logToFile("PV %s 状态为 Available，准备删除并备份\n", pv.Name)
-			if err := backupResource(&pv, BackupPvDir+currentTime); err != nil {
-				logToFile("备份 PV %s 失败: %v\n", pv.Name, err)
-			}
-			if err := client.CoreV1().PersistentVolumes().Delete(context.Background(), pv.Name, metav1.DeleteOptions{}); err != nil {
-				logToFile("删除 PV %s 失败: %v\n", pv.Name, err)
-			} else {
-				logToFile("成功删除并备份 PV: %s\n", pv.Name)
-			}
-		case "Released":
-			var pvcNamespace, pvcName string
-			if ref := pv.Spec.ClaimRef; ref != nil {
-				pvcNamespace = ref.Namespace
-				pvcName = ref.Name
-			} else {
-				logToFile("PV %s 状态为 Released，但无 ClaimRef，直接删除\n", pv.Name)
-				if err := backupResource(&pv, BackupPvDir+currentTime); err != nil {
-					logToFile("备份 PV %s 失败: %v\n", pv.Name, err)
-				}
-				if err := client.CoreV1().PersistentVolumes().Delete(context.Background(), pv.Name, metav1.DeleteOptions{}); err != nil {
-					logToFile("删除 PV %s 失败: %v\n", pv.Name, err)
-				} else {
-					logToFile("成功删除并备份 PV: %s\n", pv.Name)
-				}
-				continue
-			}
-
-			pvcInfo, err := client.CoreV1().PersistentVolumeClaims(pvcNamespace).Get(context.Background(), pvcName, metav1.GetOptions{})
-			if err != nil {
-				if errors.IsNotFound(err) {
-					logToFile("PVC %s/%s 不存在，准备删除 PV %s\n", pvcNamespace, pvcName, pv.Name)
-					if err := backupResource(&pv, BackupPvDir+currentTime); err != nil {
-						logToFile("备份 PV %s 失败: %v\n", pv.Name, err)
-					}
-					if err := client.CoreV1().PersistentVolumes().Delete(context.Background(), pv.Name, metav1.DeleteOptions{}); err != nil {
-						logToFile("删除 PV %s 失败: %v\n", pv.Name, err)
-					} else {
-						logToFile("成功删除并备份 PV: %s\n", pv.Name)
-					}
-				} else {
-					logToFile("获取 PVC %s/%s 异常: %v\n", pvcNamespace, pvcName, err)
-				}
-			} else {
-				if pv.Spec.ClaimRef.UID != "" && pv.Spec.ClaimRef.UID != pvcInfo.UID {
-					logToFile("PVC %s/%s 存在，但 UID 不匹配，准备删除 PV %s\n", pvcNamespace, pvcName, pv.Name)
-					if err := backupResource(&pv, BackupPvDir+currentTime); err != nil {
-						logToFile("备份 PV %s 失败: %v\n", pv.Name, err)
-					}
-					if err := client.CoreV1().PersistentVolumes().Delete(context.Background(), pv.Name, metav1.DeleteOptions{}); err != nil {
-						logToFile("删除 PV %s 失败: %v\n", pv.Name, err)
-					} else {
-						logToFile("成功删除并备份 PV: %s\n", pv.Name)
-					}
-				} else {
-					logToFile("PV %s 正在被 PVC 使用，跳过删除\n", pv.Name)
-				}
+// runDeleteTasks 使用固定数量的 worker 并发执行删除任务，并周期输出进度，返回成功、失败和未执行的数量。
+// ctx 结束后不再派发新任务
+func runDeleteTasks(ctx context.Context, label string, tasks []deleteTask, concurrency int) (int, int, int) {
+	if len(tasks) == 0 {
+		return 0, 0, 0
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	p := newProgress(os.Stderr, i18n.T("progress.delete", label), len(tasks), 5*time.Second)
+	taskCh := make(chan deleteTask)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range taskCh {
+				p.finish(t.run(ctx))
 			}
-		default:
-			logToFile("PV %s 状态为 %s，跳过删除\n", pv.Name, pv.Status.Phase)
+		}()
+	}
+	sent := 0
+dispatch:
+	for _, t := range tasks {
+		// select 在两个分支都就绪时随机选择，先检查 ctx 保证结束后不再派发
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case taskCh <- t:
+			sent++
+		case <-ctx.Done():
+			break dispatch
 		}
 	}
-	return nil
+	close(taskCh)
+	wg.Wait()
+	p.close()
+	failed := int(p.failed.Load())
+	skipped := len(tasks) - sent
+	if skipped > 0 {
+		logToFile("%s", i18n.T("clean.skipped", skipped, label, ctx.Err()))
+	}
+	return sent - failed, failed, skipped
 }
 func backupResource(obj runtime.Object, backupDir string) error {
 	// 创建序列化器
@@ -167,19 +253,14 @@ func backupResource(obj runtime.Object, backupDir string) error {
 	// 从对象中提取元数据
 	accessor, err := meta.Accessor(obj)
 	if err != nil {
-		return fmt.Errorf("获取对象元数据失败: %v", err)
+		return i18n.Errorf("backup.meta_failed", err)
 	}
 
-	gvk := obj.GetObjectKind().GroupVersionKind()
-	if gvk.Empty() {
-		// 尝试从注册的 scheme 中识别 GVK
-		gvks, _, err := scheme.ObjectKinds(obj)
-		if err != nil || len(gvks) == 0 {
-			return fmt.Errorf("无法从 scheme 获取 GVK: %v", err)
-		}
-		gvk = gvks[0]
-		obj.GetObjectKind().SetGroupVersionKind(gvk)
+	// 尝试从注册的 scheme 中识别 GVK
+	if err := setGroupVersionKind(obj); err != nil {
+		return err
 	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
 
 	// 构建备份路径
 	fileName := fmt.Sprintf("%s-%s.yaml", gvk.Kind, accessor.GetName())
@@ -187,28 +268,30 @@ func backupResource(obj runtime.Object, backupDir string) error {
 
 	// 确保目录存在
 	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		return fmt.Errorf("创建备份目录失败: %v", err)
+		return i18n.Errorf("backup.mkdir_failed", err)
 	}
 
 	// 打开文件
 	file, err := os.Create(filePath)
 	if err != nil {
-		return fmt.Errorf("创建备份文件失败: %v", err)
+		return i18n.Errorf("backup.create_failed", err)
 	}
 	defer file.Close()
 
 	// 执行序列化
 	if err := yamlSerializer.Encode(obj, file); err != nil {
-		return fmt.Errorf("序列化资源对象失败: %v", err)
+		return i18n.Errorf("backup.encode_failed", err)
 	}
 
 	return nil
 }
 func logToFile(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
+	logMu.Lock()
+	defer logMu.Unlock()
 	f, err := os.OpenFile(LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "无法打开日志文件: %v\n", err)
+		fmt.Fprint(os.Stderr, i18n.T("log.open_failed", err))
 		return
 	}
 	defer f.Close()
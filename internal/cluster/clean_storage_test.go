@@ -0,0 +1,164 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestListPagesContinue(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	pages := map[string]struct {
+		names []string
+		next  string
+	}{
+		"":       {[]string{"pv-1", "pv-2"}, "page-2"},
+		"page-2": {[]string{"pv-3", "pv-4"}, "page-3"},
+		"page-3": {[]string{"pv-5"}, ""},
+	}
+	var calls []string
+	client.PrependReactor("list", "persistentvolumes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		opts := action.(k8stesting.ListActionImpl).GetListOptions()
+		if opts.Limit != ListPageSize {
+			t.Errorf("Limit = %d, want %d", opts.Limit, ListPageSize)
+		}
+		calls = append(calls, opts.Continue)
+		page, ok := pages[opts.Continue]
+		if !ok {
+			return true, nil, errors.New("unexpected continue token " + opts.Continue)
+		}
+		list := &corev1.PersistentVolumeList{ListMeta: metaV1.ListMeta{Continue: page.next}}
+		for _, name := range page.names {
+			list.Items = append(list.Items, testPV(name, "Available", "1Gi"))
+		}
+		return true, list, nil
+	})
+
+	pvs, err := listPersistentVolumes(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pvs) != 5 || pvs[0].Name != "pv-1" || pvs[4].Name != "pv-5" {
+		t.Errorf("listPersistentVolumes() returned %d PVs", len(pvs))
+	}
+	if len(calls) != 3 || calls[1] != "page-2" || calls[2] != "page-3" {
+		t.Errorf("continue tokens = %q", calls)
+	}
+
+	client.PrependReactor("list", "persistentvolumes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("boom")
+	})
+	if _, err := listPersistentVolumes(context.Background(), client); err == nil {
+		t.Error("expected list error to be returned")
+	}
+}
+
+func TestRunDeleteTasks(t *testing.T) {
+	var active, maxActive atomic.Int32
+	var tasks []deleteTask
+	for i := 0; i < 8; i++ {
+		fail := i%4 == 0
+		tasks = append(tasks, deleteTask{
+			kind:      "PV",
+			name:      "pv",
+			obj:       &corev1.PersistentVolume{ObjectMeta: metaV1.ObjectMeta{Name: "pv"}},
+			backupDir: t.TempDir(),
+			delete: func(ctx context.Context) error {
+				n := active.Add(1)
+				defer active.Add(-1)
+				for {
+					m := maxActive.Load()
+					if n <= m || maxActive.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				if fail {
+					return errors.New("delete failed")
+				}
+				return nil
+			},
+		})
+	}
+	deleted, failed, skipped := runDeleteTasks(context.Background(), "PV", tasks, 4)
+	if deleted != 6 || failed != 2 || skipped != 0 {
+		t.Errorf("runDeleteTasks() = %d deleted, %d failed, %d skipped, want 6, 2, 0", deleted, failed, skipped)
+	}
+	if m := maxActive.Load(); m < 2 || m > 4 {
+		t.Errorf("max concurrent deletes = %d, want 2..4", m)
+	}
+
+	// ctx 结束后不再派发剩余任务
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls atomic.Int32
+	tasks = tasks[:0]
+	for i := 0; i < 5; i++ {
+		tasks = append(tasks, deleteTask{
+			kind:      "PV",
+			name:      "pv",
+			obj:       &corev1.PersistentVolume{ObjectMeta: metaV1.ObjectMeta{Name: "pv"}},
+			backupDir: t.TempDir(),
+			delete: func(ctx context.Context) error {
+				calls.Add(1)
+				cancel()
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			},
+		})
+	}
+	deleted, failed, skipped = runDeleteTasks(ctx, "PV", tasks, 1)
+	if deleted != 1 || failed != 0 || skipped != 4 || calls.Load() != 1 {
+		t.Errorf("cancelled runDeleteTasks() = %d deleted, %d failed, %d skipped, %d calls, want 1, 0, 4, 1", deleted, failed, skipped, calls.Load())
+	}
+}
+
+func TestDeleteCandidates(t *testing.T) {
+	sc := &storagev1.StorageClass{ObjectMeta: metaV1.ObjectMeta{Name: "unused"}}
+	pv1, pv2 := testPV("pv-1", "Available", "1Gi"), testPV("pv-2", "Available", "1Gi")
+	gone := testPV("pv-gone", "Available", "1Gi")
+	client := fake.NewSimpleClientset(sc, &pv1, &pv2)
+	candidates := []CleanupCandidate{
+		{Kind: "StorageClass", Name: sc.Name, Reason: ReasonUnusedStorageClass, Object: sc},
+		{Kind: "PV", Name: pv1.Name, Reason: ReasonAvailable, Object: &pv1},
+		{Kind: "PV", Name: pv2.Name, Reason: ReasonAvailable, Object: &pv2},
+		// 已不存在的 PV 删除失败，计入失败数
+		{Kind: "PV", Name: gone.Name, Reason: ReasonAvailable, Object: &gone},
+	}
+
+	dir := t.TempDir()
+	dry := DeleteCandidates(context.Background(), client, candidates, CleanOptions{DryRun: true, BackupDir: dir, Concurrency: 2})
+	if dry.Candidates != 4 || dry.Deleted != 0 || dry.Failed != 0 {
+		t.Errorf("dry-run result = %+v", dry)
+	}
+	if pvs, _ := client.CoreV1().PersistentVolumes().List(context.Background(), metaV1.ListOptions{}); len(pvs.Items) != 2 {
+		t.Fatalf("dry-run deleted PVs, %d left", len(pvs.Items))
+	}
+
+	result := DeleteCandidates(context.Background(), client, candidates, CleanOptions{BackupDir: dir, Concurrency: 2})
+	if result.Candidates != 4 || result.Deleted != 3 || result.Failed != 1 || result.Err() == nil {
+		t.Errorf("result = %+v", result)
+	}
+	if pvs, _ := client.CoreV1().PersistentVolumes().List(context.Background(), metaV1.ListOptions{}); len(pvs.Items) != 0 {
+		t.Errorf("%d PVs left after cleanup", len(pvs.Items))
+	}
+	if scs, _ := client.StorageV1().StorageClasses().List(context.Background(), metaV1.ListOptions{}); len(scs.Items) != 0 {
+		t.Errorf("%d StorageClasses left after cleanup", len(scs.Items))
+	}
+	backups, _ := filepath.Glob(filepath.Join(dir, "pv*", "PersistentVolume-pv-1.yaml"))
+	if len(backups) != 1 {
+		t.Errorf("backup of pv-1 not found under %s", dir)
+	} else if data, _ := os.ReadFile(backups[0]); len(data) == 0 {
+		t.Error("backup of pv-1 is empty")
+	}
+}
@@ -0,0 +1,41 @@
+package cluster
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"strings"
+)
+
+// CSIDecoder 描述如何从 CSI PV 中解析类型和位置，由配置文件的 csi 段设置
+type CSIDecoder struct {
+	// Driver CSI 驱动名称，例如 nfs.csi.k8s.io
+	Driver string `json:"driver"`
+	// Type get-pv 中展示的类型，为空时使用驱动名称
+	Type string `json:"type,omitempty"`
+	// LocationAttributes 按顺序取 volumeAttributes 中的值，以 : 连接作为位置，为空时使用 volumeHandle
+	LocationAttributes []string `json:"locationAttributes,omitempty"`
+}
+
+// CSIDecoders 已配置的 CSI 解析规则
+var CSIDecoders []CSIDecoder
+
+// decodeCSI 返回 CSI PV 的类型和位置，未配置的驱动类型为 csi，位置为 volumeHandle
+func decodeCSI(csi *corev1.CSIPersistentVolumeSource) (string, string) {
+	for _, d := range CSIDecoders {
+		if d.Driver != csi.Driver {
+			continue
+		}
+		typ := d.Type
+		if typ == "" {
+			typ = d.Driver
+		}
+		if len(d.LocationAttributes) == 0 {
+			return typ, csi.VolumeHandle
+		}
+		parts := make([]string, 0, len(d.LocationAttributes))
+		for _, key := range d.LocationAttributes {
+			parts = append(parts, csi.VolumeAttributes[key])
+		}
+		return typ, strings.Join(parts, ":")
+	}
+	return "csi", csi.VolumeHandle
+}
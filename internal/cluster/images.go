@@ -0,0 +1,242 @@
+package cluster
+
+import (
+	"devops_tools/internal/i18n"
+	"fmt"
+	"github.com/tealeg/xlsx/v3"
+	corev1 "k8s.io/api/core/v1"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// defaultRegistry 镜像引用中未写仓库地址时使用的仓库
+const defaultRegistry = "docker.io"
+
+// ImageIssue get-images 标记的镜像问题
+type ImageIssue string
+
+const (
+	// IssueLatestTag 使用 latest 或未指定 tag 且没有固定 digest
+	IssueLatestTag ImageIssue = "latest-tag"
+	// IssueRegistryNotAllowed 仓库不在 --allowed-registry 中
+	IssueRegistryNotAllowed ImageIssue = "registry-not-allowed"
+	// IssueNotRunning 只出现在工作负载模板中，没有运行中的 Pod 使用
+	IssueNotRunning ImageIssue = "not-running"
+)
+
+// ImageUsage 镜像的一处使用，Kind 为 Pod 或工作负载类型
+type ImageUsage struct {
+	Namespace  string
+	Kind       string
+	Name       string
+	Container  string
+	Init       bool
+	PullPolicy corev1.PullPolicy
+}
+
+// ImageInfo get-images 中一个镜像的汇总信息
+type ImageInfo struct {
+	Image    string
+	Registry string
+	Repo     string
+	Tag      string
+	// Pods 未结束的 Pod 中使用该镜像的容器数，RunningPods 其中处于 Running 的 Pod 数
+	Pods        int
+	RunningPods int
+	Templates   int
+	Namespaces  []string
+	PullPolicy  []string
+	// Digests Pod status 中实际拉取的镜像 digest
+	Digests []string
+	Usages  []ImageUsage
+	Issues  []ImageIssue
+}
+
+// ImageOptions get-images 的参数
+type ImageOptions struct {
+	// AllowedRegistries 允许的仓库，可以带路径前缀，例如 harbor.example.com/library，为空时不检查
+	AllowedRegistries []string
+	Namespace         string
+	// IssuesOnly 只输出存在问题的镜像
+	IssuesOnly bool
+}
+
+// ImageInfos 汇总 Pod 和工作负载模板中的全部容器及 init 容器镜像，按镜像名称排序
+func (s *Snapshot) ImageInfos(opts ImageOptions) []ImageInfo {
+	byImage := make(map[string]*ImageInfo)
+	namespaces := make(map[string]map[string]bool)
+	policies := make(map[string]map[string]bool)
+	digests := make(map[string]map[string]bool)
+	add := func(image string, usage ImageUsage) *ImageInfo {
+		info, ok := byImage[image]
+		if !ok {
+			repo, tag, _ := splitImage(image)
+			registry, _ := splitRegistry(repo)
+			info = &ImageInfo{Image: image, Registry: registry, Repo: repo, Tag: tag}
+			byImage[image] = info
+			namespaces[image], policies[image], digests[image] = map[string]bool{}, map[string]bool{}, map[string]bool{}
+		}
+		info.Usages = append(info.Usages, usage)
+		namespaces[image][usage.Namespace] = true
+		if usage.PullPolicy != "" {
+			policies[image][string(usage.PullPolicy)] = true
+		}
+		return info
+	}
+	containers := func(spec *corev1.PodSpec, fn func(c corev1.Container, init bool)) {
+		for _, c := range spec.InitContainers {
+			fn(c, true)
+		}
+		for _, c := range spec.Containers {
+			fn(c, false)
+		}
+	}
+
+	for i := range s.Pods {
+		pod := &s.Pods[i]
+		if (opts.Namespace != "" && pod.Namespace != opts.Namespace) || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		imageIDs := make(map[string]string)
+		for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+			for _, st := range statuses {
+				imageIDs[st.Name] = st.ImageID
+			}
+		}
+		counted := make(map[string]bool)
+		containers(&pod.Spec, func(c corev1.Container, init bool) {
+			info := add(c.Image, ImageUsage{Namespace: pod.Namespace, Kind: "Pod", Name: pod.Name, Container: c.Name, Init: init, PullPolicy: c.ImagePullPolicy})
+			info.Pods++
+			if pod.Status.Phase == corev1.PodRunning && !counted[c.Image] {
+				info.RunningPods++
+				counted[c.Image] = true
+			}
+			if _, digest, ok := strings.Cut(imageIDs[c.Name], "@"); ok {
+				digests[c.Image][digest] = true
+			}
+		})
+	}
+	for _, w := range s.workloads() {
+		if opts.Namespace != "" && w.meta.Namespace != opts.Namespace {
+			continue
+		}
+		containers(w.spec, func(c corev1.Container, init bool) {
+			info := add(c.Image, ImageUsage{Namespace: w.meta.Namespace, Kind: w.kind, Name: w.meta.Name, Container: c.Name, Init: init, PullPolicy: c.ImagePullPolicy})
+			info.Templates++
+		})
+	}
+
+	infos := make([]ImageInfo, 0, len(byImage))
+	for _, image := range sortedKeys(byImage) {
+		info := byImage[image]
+		info.Namespaces = sortedKeys(namespaces[image])
+		info.PullPolicy = sortedKeys(policies[image])
+		info.Digests = sortedKeys(digests[image])
+		_, _, digest := splitImage(image)
+		if info.Tag == "latest" && digest == "" {
+			info.Issues = append(info.Issues, IssueLatestTag)
+		}
+		if len(opts.AllowedRegistries) > 0 && !registryAllowed(info.Repo, opts.AllowedRegistries) {
+			info.Issues = append(info.Issues, IssueRegistryNotAllowed)
+		}
+		// CronJob 和 Job 的 Pod 只在执行期间存在，不标记为未运行
+		if info.Templates > 0 && info.RunningPods == 0 && !onlyBatch(info.Usages) {
+			info.Issues = append(info.Issues, IssueNotRunning)
+		}
+		if opts.IssuesOnly && len(info.Issues) == 0 {
+			continue
+		}
+		infos = append(infos, *info)
+	}
+	return infos
+}
+
+// onlyBatch 判断镜像的模板引用是否全部来自 CronJob 和 Job
+func onlyBatch(usages []ImageUsage) bool {
+	for _, u := range usages {
+		if u.Kind != "Pod" && u.Kind != KindCronJob && u.Kind != KindJob {
+			return false
+		}
+	}
+	return true
+}
+
+// splitRegistry 拆分仓库地址和镜像路径。第一段包含 . 或 : 或为 localhost 时视为仓库地址，否则为 docker.io。
+// docker.io 上只有一段的路径是官方镜像，补全为 library/<name>，redis 与 docker.io/library/redis 视为同一仓库
+func splitRegistry(repo string) (registry, path string) {
+	registry, path = defaultRegistry, repo
+	if first, rest, ok := strings.Cut(repo, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		registry, path = first, rest
+	}
+	if registry == defaultRegistry && !strings.Contains(path, "/") {
+		path = "library/" + path
+	}
+	return registry, path
+}
+
+// registryAllowed 判断镜像仓库是否匹配允许列表中的仓库地址或路径前缀
+func registryAllowed(repo string, allowed []string) bool {
+	registry, path := splitRegistry(repo)
+	full := registry + "/" + path
+	for _, a := range allowed {
+		a = strings.TrimSuffix(a, "/")
+		if a == registry || full == a || strings.HasPrefix(full, a+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func issueList(issues []ImageIssue) []string {
+	list := make([]string, len(issues))
+	for i, issue := range issues {
+		list[i] = string(issue)
+	}
+	return list
+}
+
+// GetImageInfo 输出镜像清单和问题标记。导出 Excel 时额外写入逐条列出使用位置的工作表
+func GetImageInfo(snap *Snapshot, filePath string, opts ImageOptions) error {
+	infos := snap.ImageInfos(opts)
+	if filePath == "" {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, i18n.Header("IMAGE", "REGISTRY", "PODS", "TEMPLATES", "NAMESPACES", "PULL POLICY", "DIGEST", "ISSUES"))
+		for _, img := range infos {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\n",
+				img.Image, img.Registry, img.Pods, img.Templates, joinOrDash(img.Namespaces), joinOrDash(img.PullPolicy),
+				joinOrDash(img.Digests), joinOrDash(issueList(img.Issues)))
+		}
+		return w.Flush()
+	}
+
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Images")
+	if err != nil {
+		return err
+	}
+	sheet.AddRow().WriteSlice([]string{"IMAGE", "REGISTRY", "REPOSITORY", "TAG", "PODS", "RUNNING PODS", "TEMPLATES",
+		"NAMESPACES", "PULL POLICY", "DIGEST", "ISSUES"}, -1)
+	usageSheet, err := file.AddSheet("Usages")
+	if err != nil {
+		return err
+	}
+	usageSheet.AddRow().WriteSlice([]string{"IMAGE", "NAMESPACE", "KIND", "NAME", "CONTAINER", "INIT", "PULL POLICY"}, -1)
+	for _, img := range infos {
+		sheet.AddRow().WriteSlice([]interface{}{
+			img.Image, img.Registry, img.Repo, img.Tag, img.Pods, img.RunningPods, img.Templates,
+			strings.Join(img.Namespaces, ","), strings.Join(img.PullPolicy, ","), strings.Join(img.Digests, ","), strings.Join(issueList(img.Issues), ","),
+		}, -1)
+		sort.SliceStable(img.Usages, func(i, j int) bool { return img.Usages[i].Namespace < img.Usages[j].Namespace })
+		for _, u := range img.Usages {
+			usageSheet.AddRow().WriteSlice([]interface{}{img.Image, u.Namespace, u.Kind, u.Name, u.Container, u.Init, string(u.PullPolicy)}, -1)
+		}
+	}
+	if err := file.Save(filePath); err != nil {
+		return err
+	}
+	fmt.Print(i18n.T("image.file_written", filePath))
+	return nil
+}
@@ -0,0 +1,86 @@
+package cluster
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	bv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestImageInfos(t *testing.T) {
+	template := func(image string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}}}
+	}
+	snap := &Snapshot{
+		Deployments: []appsv1.Deployment{
+			{ObjectMeta: metaV1.ObjectMeta{Name: "web", Namespace: "ns"}, Spec: appsv1.DeploymentSpec{Template: template("harbor.local/app/web:1.0")}},
+			{ObjectMeta: metaV1.ObjectMeta{Name: "idle", Namespace: "ns"}, Spec: appsv1.DeploymentSpec{Template: template("redis")}},
+		},
+		CronJobs: []bv1.CronJob{{ObjectMeta: metaV1.ObjectMeta{Name: "report", Namespace: "ns"},
+			Spec: bv1.CronJobSpec{JobTemplate: bv1.JobTemplateSpec{Spec: bv1.JobSpec{Template: template("harbor.local/app/report:2")}}}}},
+		Pods: []corev1.Pod{{
+			ObjectMeta: metaV1.ObjectMeta{Name: "web-1", Namespace: "ns"},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init", Image: "busybox:latest"}},
+				Containers:     []corev1.Container{{Name: "app", Image: "harbor.local/app/web:1.0", ImagePullPolicy: corev1.PullIfNotPresent}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", ImageID: "harbor.local/app/web@sha256:abc"},
+			}},
+		}},
+	}
+	snap.buildIndexes()
+	issues := make(map[string]string)
+	for _, img := range snap.ImageInfos(ImageOptions{AllowedRegistries: []string{"harbor.local/app"}}) {
+		issues[img.Image] = joinOrDash(issueList(img.Issues))
+		if img.Image == "harbor.local/app/web:1.0" && (img.Pods != 1 || img.Templates != 1 || joinOrDash(img.Digests) != "sha256:abc") {
+			t.Errorf("web image = %+v", img)
+		}
+	}
+	want := map[string]string{
+		"harbor.local/app/web:1.0":  "-",
+		"harbor.local/app/report:2": "-",
+		"busybox:latest":            "latest-tag,registry-not-allowed",
+		"redis":                     "latest-tag,registry-not-allowed,not-running",
+	}
+	for image, w := range want {
+		if issues[image] != w {
+			t.Errorf("issues of %s = %s, want %s", image, issues[image], w)
+		}
+	}
+	if got := snap.ImageInfos(ImageOptions{IssuesOnly: true}); len(got) != 2 {
+		t.Errorf("IssuesOnly returned %d images", len(got))
+	}
+
+	// 官方镜像补全为 docker.io/library/<name>，可以用 docker.io/library 放行
+	for _, img := range snap.ImageInfos(ImageOptions{AllowedRegistries: []string{"harbor.local", "docker.io/library"}}) {
+		for _, issue := range img.Issues {
+			if issue == IssueRegistryNotAllowed {
+				t.Errorf("%s should be allowed by docker.io/library", img.Image)
+			}
+		}
+	}
+}
+
+func TestSplitRegistry(t *testing.T) {
+	for _, tc := range []struct {
+		repo, registry, path string
+	}{
+		{"redis", "docker.io", "library/redis"},
+		{"docker.io/redis", "docker.io", "library/redis"},
+		{"docker.io/library/redis", "docker.io", "library/redis"},
+		{"bitnami/redis", "docker.io", "bitnami/redis"},
+		{"harbor.local/app/web", "harbor.local", "app/web"},
+		{"harbor.local/web", "harbor.local", "web"},
+		{"localhost:5000/web", "localhost:5000", "web"},
+		{"localhost/web", "localhost", "web"},
+	} {
+		if registry, path := splitRegistry(tc.repo); registry != tc.registry || path != tc.path {
+			t.Errorf("splitRegistry(%s) = %s, %s, want %s, %s", tc.repo, registry, path, tc.registry, tc.path)
+		}
+	}
+	if !registryAllowed("redis", []string{"docker.io/library/redis"}) || registryAllowed("bitnami/redis", []string{"docker.io/library"}) {
+		t.Error("registryAllowed() does not match docker.io/library paths")
+	}
+}
@@ -0,0 +1,158 @@
+package cluster
+
+import (
+	"context"
+	appsv1 "k8s.io/api/apps/v1"
+	bv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"time"
+)
+
+const (
+	// ListPageSize 每次分页 LIST 请求返回的最大条目数
+	ListPageSize = 500
+	// pageTimeout 单页请求的超时时间，整体耗时不受限制
+	pageTimeout = 60 * time.Second
+)
+
+// listPages 通过 Limit/Continue 分页拉取全部资源，每一页单独计算超时
+func listPages[T any](ctx context.Context, list func(ctx context.Context, opts metaV1.ListOptions) ([]T, string, error)) ([]T, error) {
+	var items []T
+	opts := metaV1.ListOptions{Limit: ListPageSize}
+	for {
+		pageCtx, cancel := context.WithTimeout(ctx, pageTimeout)
+		page, next, err := list(pageCtx, opts)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		if next == "" {
+			return items, nil
+		}
+		opts.Continue = next
+	}
+}
+
+func listStorageClasses(ctx context.Context, client kubernetes.Interface) ([]storagev1.StorageClass, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]storagev1.StorageClass, string, error) {
+		l, err := client.StorageV1().StorageClasses().List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
+
+func listNamespaces(ctx context.Context, client kubernetes.Interface) ([]corev1.Namespace, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]corev1.Namespace, string, error) {
+		l, err := client.CoreV1().Namespaces().List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
+
+func listNodes(ctx context.Context, client kubernetes.Interface) ([]corev1.Node, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]corev1.Node, string, error) {
+		l, err := client.CoreV1().Nodes().List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
+
+func listPersistentVolumes(ctx context.Context, client kubernetes.Interface) ([]corev1.PersistentVolume, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]corev1.PersistentVolume, string, error) {
+		l, err := client.CoreV1().PersistentVolumes().List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
+
+func listPersistentVolumeClaims(ctx context.Context, client kubernetes.Interface) ([]corev1.PersistentVolumeClaim, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]corev1.PersistentVolumeClaim, string, error) {
+		l, err := client.CoreV1().PersistentVolumeClaims("").List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
+
+func listPods(ctx context.Context, client kubernetes.Interface) ([]corev1.Pod, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]corev1.Pod, string, error) {
+		l, err := client.CoreV1().Pods("").List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
+
+func listDeployments(ctx context.Context, client kubernetes.Interface) ([]appsv1.Deployment, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]appsv1.Deployment, string, error) {
+		l, err := client.AppsV1().Deployments("").List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
+
+func listDaemonSets(ctx context.Context, client kubernetes.Interface) ([]appsv1.DaemonSet, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]appsv1.DaemonSet, string, error) {
+		l, err := client.AppsV1().DaemonSets("").List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
+
+func listStatefulSets(ctx context.Context, client kubernetes.Interface) ([]appsv1.StatefulSet, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]appsv1.StatefulSet, string, error) {
+		l, err := client.AppsV1().StatefulSets("").List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
+
+func listCronJobs(ctx context.Context, client kubernetes.Interface) ([]bv1.CronJob, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]bv1.CronJob, string, error) {
+		l, err := client.BatchV1().CronJobs("").List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
+
+func listJobs(ctx context.Context, client kubernetes.Interface) ([]bv1.Job, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]bv1.Job, string, error) {
+		l, err := client.BatchV1().Jobs("").List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
+
+func listResourceQuotas(ctx context.Context, client kubernetes.Interface) ([]corev1.ResourceQuota, error) {
+	return listPages(ctx, func(ctx context.Context, opts metaV1.ListOptions) ([]corev1.ResourceQuota, string, error) {
+		l, err := client.CoreV1().ResourceQuotas("").List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return l.Items, l.Continue, nil
+	})
+}
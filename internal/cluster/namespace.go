@@ -0,0 +1,185 @@
+package cluster
+
+import (
+	"devops_tools/internal/i18n"
+	"fmt"
+	"github.com/tealeg/xlsx/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// QuotaUsage ResourceQuota 中一项资源的已用量和上限
+type QuotaUsage struct {
+	Quota    string
+	Resource corev1.ResourceName
+	Used     resource.Quantity
+	Hard     resource.Quantity
+}
+
+// NamespaceInfo get-ns 中一个命名空间的展示信息
+type NamespaceInfo struct {
+	Name   string
+	Status corev1.NamespacePhase
+	// StorageClasses 通过 dophin/storage 注解绑定的 StorageClass
+	StorageClasses []string
+	Quotas         []QuotaUsage
+	PVCs           int
+	// RequestedStorage PVC 申请容量之和
+	RequestedStorage resource.Quantity
+	Deployments      int
+	StatefulSets     int
+	DaemonSets       int
+	CronJobs         int
+	Jobs             int
+	Pods             int
+	Age              time.Duration
+}
+
+// NamespaceInfos 汇总每个命名空间的存储、配额和工作负载，按名称排序
+func (s *Snapshot) NamespaceInfos() []NamespaceInfo {
+	byName := make(map[string]*NamespaceInfo, len(s.Namespaces))
+	infos := make([]NamespaceInfo, len(s.Namespaces))
+	for i, ns := range s.Namespaces {
+		infos[i] = NamespaceInfo{
+			Name:   ns.Name,
+			Status: ns.Status.Phase,
+			Age:    time.Since(ns.CreationTimestamp.Time).Round(time.Second),
+		}
+		for _, sc := range strings.Split(ns.Annotations[StorageAnnotation], ",") {
+			if sc != "" {
+				infos[i].StorageClasses = append(infos[i].StorageClasses, sc)
+			}
+		}
+		byName[ns.Name] = &infos[i]
+	}
+	// 快照中不存在的命名空间（例如只导出了部分资源）下的资源直接忽略
+	count := func(namespace string, fn func(info *NamespaceInfo)) {
+		if info, ok := byName[namespace]; ok {
+			fn(info)
+		}
+	}
+	for _, q := range s.ResourceQuotas {
+		count(q.Namespace, func(info *NamespaceInfo) { info.Quotas = append(info.Quotas, quotaUsages(q)...) })
+	}
+	for _, pvc := range s.PersistentVolumeClaims {
+		count(pvc.Namespace, func(info *NamespaceInfo) {
+			info.PVCs++
+			if req, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+				info.RequestedStorage.Add(req)
+			}
+		})
+	}
+	for _, d := range s.Deployments {
+		count(d.Namespace, func(info *NamespaceInfo) { info.Deployments++ })
+	}
+	for _, sts := range s.StatefulSets {
+		count(sts.Namespace, func(info *NamespaceInfo) { info.StatefulSets++ })
+	}
+	for _, ds := range s.DaemonSets {
+		count(ds.Namespace, func(info *NamespaceInfo) { info.DaemonSets++ })
+	}
+	for _, cj := range s.CronJobs {
+		count(cj.Namespace, func(info *NamespaceInfo) { info.CronJobs++ })
+	}
+	for _, job := range s.Jobs {
+		count(job.Namespace, func(info *NamespaceInfo) { info.Jobs++ })
+	}
+	for _, pod := range s.Pods {
+		count(pod.Namespace, func(info *NamespaceInfo) { info.Pods++ })
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// quotaUsages 按资源名称排序展开 ResourceQuota 的 hard 和 used
+func quotaUsages(q corev1.ResourceQuota) []QuotaUsage {
+	names := make([]string, 0, len(q.Status.Hard))
+	hard := q.Status.Hard
+	// 控制器尚未同步 status 时使用 spec 中的上限
+	if len(hard) == 0 {
+		hard = q.Spec.Hard
+	}
+	for name := range hard {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	usages := make([]QuotaUsage, 0, len(names))
+	for _, name := range names {
+		r := corev1.ResourceName(name)
+		usages = append(usages, QuotaUsage{Quota: q.Name, Resource: r, Used: q.Status.Used[r], Hard: hard[r]})
+	}
+	return usages
+}
+
+// Percent 已用量占上限的百分比，上限为 0 时返回 0
+func (u QuotaUsage) Percent() float64 {
+	return percent(u.Used, u.Hard)
+}
+
+// quotaSummary 表格中显示的配额摘要，例如 requests.cpu=2/4,requests.storage=50Gi/100Gi
+func (n NamespaceInfo) quotaSummary() string {
+	parts := make([]string, 0, len(n.Quotas))
+	for _, q := range n.Quotas {
+		parts = append(parts, fmt.Sprintf("%s=%s/%s", q.Resource, q.Used.String(), q.Hard.String()))
+	}
+	return strings.Join(parts, ",")
+}
+
+// GetNamespaceInfo 输出命名空间清单，names 非空时只输出这些命名空间。
+// 导出 Excel 时额外写入一个逐项列出配额用量的工作表
+func GetNamespaceInfo(snap *Snapshot, filePath string, names []string) error {
+	header := []string{"NAME", "STATUS", "STORAGECLASSES", "QUOTA", "PVCS", "REQUESTED STORAGE",
+		"DEPLOYMENTS", "STATEFULSETS", "DAEMONSETS", "CRONJOBS", "JOBS", "PODS", "AGE"}
+	wanted := toSet(names)
+	var infos []NamespaceInfo
+	for _, ns := range snap.NamespaceInfos() {
+		if len(wanted) == 0 || wanted[ns.Name] {
+			infos = append(infos, ns)
+		}
+	}
+
+	if filePath == "" {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, i18n.Header(header...))
+		for _, ns := range infos {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n",
+				ns.Name, ns.Status, strings.Join(ns.StorageClasses, ","), ns.quotaSummary(), ns.PVCs, ns.RequestedStorage.String(),
+				ns.Deployments, ns.StatefulSets, ns.DaemonSets, ns.CronJobs, ns.Jobs, ns.Pods, ns.Age)
+		}
+		return w.Flush()
+	}
+
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Namespaces")
+	if err != nil {
+		return err
+	}
+	sheet.AddRow().WriteSlice(header, -1)
+	quotaSheet, err := file.AddSheet("ResourceQuotas")
+	if err != nil {
+		return err
+	}
+	quotaSheet.AddRow().WriteSlice([]string{"NAMESPACE", "QUOTA", "RESOURCE", "USED", "HARD", "USED %"}, -1)
+	for _, ns := range infos {
+		sheet.AddRow().WriteSlice([]interface{}{
+			ns.Name, string(ns.Status), strings.Join(ns.StorageClasses, ","), ns.quotaSummary(), ns.PVCs, ns.RequestedStorage.String(),
+			ns.Deployments, ns.StatefulSets, ns.DaemonSets, ns.CronJobs, ns.Jobs, ns.Pods, ns.Age.String(),
+		}, -1)
+		for _, q := range ns.Quotas {
+			quotaSheet.AddRow().WriteSlice([]interface{}{
+				ns.Name, q.Quota, string(q.Resource), q.Used.String(), q.Hard.String(), fmt.Sprintf("%.1f", q.Percent()),
+			}, -1)
+		}
+	}
+	if err := file.Save(filePath); err != nil {
+		return err
+	}
+	fmt.Print(i18n.T("ns.file_written", filePath))
+	return nil
+}
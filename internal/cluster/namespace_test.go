@@ -0,0 +1,54 @@
+package cluster
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestNamespaceInfos(t *testing.T) {
+	pvc := func(name, size string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metaV1.ObjectMeta{Name: name, Namespace: "tenant"},
+			Spec: corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			}},
+		}
+	}
+	snap := &Snapshot{
+		Namespaces: []corev1.Namespace{
+			{ObjectMeta: metaV1.ObjectMeta{Name: "tenant", Annotations: map[string]string{StorageAnnotation: "local,nfs"}}},
+			{ObjectMeta: metaV1.ObjectMeta{Name: "empty"}},
+		},
+		PersistentVolumeClaims: []corev1.PersistentVolumeClaim{pvc("a", "10Gi"), pvc("b", "512Mi")},
+		Deployments:            []appsv1.Deployment{{ObjectMeta: metaV1.ObjectMeta{Name: "web", Namespace: "tenant"}}},
+		// 不在快照中的命名空间下的资源不计入
+		StatefulSets: []appsv1.StatefulSet{{ObjectMeta: metaV1.ObjectMeta{Name: "db", Namespace: "gone"}}},
+		ResourceQuotas: []corev1.ResourceQuota{{
+			ObjectMeta: metaV1.ObjectMeta{Name: "q", Namespace: "tenant"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourceRequestsStorage: resource.MustParse("100Gi"), corev1.ResourceRequestsCPU: resource.MustParse("4")},
+				Used: corev1.ResourceList{corev1.ResourceRequestsStorage: resource.MustParse("25Gi")},
+			},
+		}},
+	}
+	infos := snap.NamespaceInfos()
+	if len(infos) != 2 || infos[0].Name != "empty" || infos[1].Name != "tenant" {
+		t.Fatalf("NamespaceInfos() = %+v", infos)
+	}
+	ns := infos[1]
+	if ns.PVCs != 2 || ns.RequestedStorage.String() != "10752Mi" || ns.Deployments != 1 || ns.StatefulSets != 0 {
+		t.Errorf("tenant = %+v", ns)
+	}
+	if len(ns.StorageClasses) != 2 {
+		t.Errorf("StorageClasses = %v", ns.StorageClasses)
+	}
+	if got, want := ns.quotaSummary(), "requests.cpu=0/4,requests.storage=25Gi/100Gi"; got != want {
+		t.Errorf("quotaSummary() = %s, want %s", got, want)
+	}
+	if p := ns.Quotas[1].Percent(); p != 25 {
+		t.Errorf("Percent() = %v, want 25", p)
+	}
+}
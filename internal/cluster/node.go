@@ -0,0 +1,217 @@
+package cluster
+
+import (
+	"devops_tools/internal/i18n"
+	"fmt"
+	"github.com/tealeg/xlsx/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// nodeRolePrefix 节点角色标签前缀，例如 node-role.kubernetes.io/control-plane
+const nodeRolePrefix = "node-role.kubernetes.io/"
+
+// pressureConditions get-node 关注的压力状态
+var pressureConditions = []corev1.NodeConditionType{corev1.NodeDiskPressure, corev1.NodeMemoryPressure, corev1.NodePIDPressure}
+
+// NodeInfo get-node 中一个节点的展示信息
+type NodeInfo struct {
+	Name           string
+	Status         string
+	Roles          []string
+	KubeletVersion string
+	RuntimeVersion string
+	OSImage        string
+	CPUAllocatable resource.Quantity
+	CPURequested   resource.Quantity
+	MemAllocatable resource.Quantity
+	MemRequested   resource.Quantity
+	// Pressure 处于 True 状态的 DiskPressure、MemoryPressure、PIDPressure
+	Pressure        []string
+	Taints          []string
+	LocalPVs        int
+	LocalPVCapacity resource.Quantity
+	Pods            int
+	Age             time.Duration
+}
+
+// NodeInfos 汇总每个节点的版本、资源分配、健康状态和 local PV，按名称排序。
+// 已请求资源只统计调度到该节点且未结束的 Pod
+func (s *Snapshot) NodeInfos() []NodeInfo {
+	infos := make([]NodeInfo, len(s.Nodes))
+	byName := make(map[string]*NodeInfo, len(s.Nodes))
+	for i, node := range s.Nodes {
+		info := NodeInfo{
+			Name:           node.Name,
+			Status:         nodeStatus(node),
+			KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+			RuntimeVersion: node.Status.NodeInfo.ContainerRuntimeVersion,
+			OSImage:        node.Status.NodeInfo.OSImage,
+			CPUAllocatable: node.Status.Allocatable[corev1.ResourceCPU],
+			MemAllocatable: node.Status.Allocatable[corev1.ResourceMemory],
+			Age:            time.Since(node.CreationTimestamp.Time).Round(time.Second),
+		}
+		for label := range node.Labels {
+			if role, ok := strings.CutPrefix(label, nodeRolePrefix); ok && role != "" {
+				info.Roles = append(info.Roles, role)
+			}
+		}
+		sort.Strings(info.Roles)
+		for _, c := range node.Status.Conditions {
+			for _, p := range pressureConditions {
+				if c.Type == p && c.Status == corev1.ConditionTrue {
+					info.Pressure = append(info.Pressure, string(p))
+				}
+			}
+		}
+		for _, t := range node.Spec.Taints {
+			taint := t.Key
+			if t.Value != "" {
+				taint += "=" + t.Value
+			}
+			info.Taints = append(info.Taints, taint+":"+string(t.Effect))
+		}
+		for _, pv := range s.LocalPVsOnNode(node.Name) {
+			info.LocalPVs++
+			info.LocalPVCapacity.Add(*pv.Spec.Capacity.Storage())
+		}
+		infos[i] = info
+		byName[node.Name] = &infos[i]
+	}
+	for i := range s.Pods {
+		pod := &s.Pods[i]
+		info, ok := byName[pod.Spec.NodeName]
+		if !ok || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		info.Pods++
+		requests := podResources(&pod.Spec, false)
+		info.CPURequested.Add(requests[corev1.ResourceCPU])
+		info.MemRequested.Add(requests[corev1.ResourceMemory])
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// nodeStatus 与 kubectl get node 一致：Ready/NotReady/Unknown，禁止调度时追加 SchedulingDisabled
+func nodeStatus(node corev1.Node) string {
+	status := "Unknown"
+	for _, c := range node.Status.Conditions {
+		if c.Type != corev1.NodeReady {
+			continue
+		}
+		switch c.Status {
+		case corev1.ConditionTrue:
+			status = "Ready"
+		case corev1.ConditionFalse:
+			status = "NotReady"
+		}
+	}
+	if node.Spec.Unschedulable {
+		status += ",SchedulingDisabled"
+	}
+	return status
+}
+
+// podResources 计算调度器视角的 Pod 资源请求（limits 为 true 时为限制）：
+// 业务容器之和与单个 init 容器取较大值，再加上 Overhead
+func podResources(spec *corev1.PodSpec, limits bool) corev1.ResourceList {
+	pick := func(c corev1.Container) corev1.ResourceList {
+		if limits {
+			return c.Resources.Limits
+		}
+		return c.Resources.Requests
+	}
+	total := corev1.ResourceList{}
+	for _, c := range spec.Containers {
+		for name, q := range pick(c) {
+			sum := total[name]
+			sum.Add(q)
+			total[name] = sum
+		}
+	}
+	for _, c := range spec.InitContainers {
+		for name, q := range pick(c) {
+			if cur, ok := total[name]; !ok || q.Cmp(cur) > 0 {
+				total[name] = q.DeepCopy()
+			}
+		}
+	}
+	for name, q := range spec.Overhead {
+		sum := total[name]
+		sum.Add(q)
+		total[name] = sum
+	}
+	return total
+}
+
+// percent requested 占 allocatable 的百分比，allocatable 为 0 时返回 0
+func percent(requested, allocatable resource.Quantity) float64 {
+	if allocatable.IsZero() {
+		return 0
+	}
+	return float64(requested.MilliValue()) / float64(allocatable.MilliValue()) * 100
+}
+
+// joinOrDash 拼接列表，为空时返回 -，避免表格中出现空列
+func joinOrDash(items []string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, ",")
+}
+
+// GetNodeInfo 输出节点清单，names 非空时只输出这些节点
+func GetNodeInfo(snap *Snapshot, filePath string, names []string) error {
+	wanted := toSet(names)
+	var infos []NodeInfo
+	for _, node := range snap.NodeInfos() {
+		if len(wanted) == 0 || wanted[node.Name] {
+			infos = append(infos, node)
+		}
+	}
+
+	if filePath == "" {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, i18n.Header("NAME", "STATUS", "ROLES", "KUBELET", "RUNTIME", "OS IMAGE", "CPU REQUESTS", "MEMORY REQUESTS",
+			"PRESSURE", "TAINTS", "LOCAL PVS", "PODS", "AGE"))
+		for _, n := range infos {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s/%s (%.0f%%)\t%s/%s (%.0f%%)\t%s\t%s\t%d (%s)\t%d\t%s\n",
+				n.Name, n.Status, joinOrDash(n.Roles), n.KubeletVersion, n.RuntimeVersion, n.OSImage,
+				n.CPURequested.String(), n.CPUAllocatable.String(), percent(n.CPURequested, n.CPUAllocatable),
+				n.MemRequested.String(), n.MemAllocatable.String(), percent(n.MemRequested, n.MemAllocatable),
+				joinOrDash(n.Pressure), joinOrDash(n.Taints), n.LocalPVs, n.LocalPVCapacity.String(), n.Pods, n.Age)
+		}
+		return w.Flush()
+	}
+
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Nodes")
+	if err != nil {
+		return err
+	}
+	sheet.AddRow().WriteSlice([]string{
+		"NAME", "STATUS", "ROLES", "KUBELET", "RUNTIME", "OS IMAGE",
+		"CPU ALLOCATABLE", "CPU REQUESTED", "CPU %", "MEMORY ALLOCATABLE", "MEMORY REQUESTED", "MEMORY %",
+		"PRESSURE", "TAINTS", "LOCAL PVS", "LOCAL PV CAPACITY", "PODS", "AGE",
+	}, -1)
+	for _, n := range infos {
+		sheet.AddRow().WriteSlice([]interface{}{
+			n.Name, n.Status, strings.Join(n.Roles, ","), n.KubeletVersion, n.RuntimeVersion, n.OSImage,
+			n.CPUAllocatable.String(), n.CPURequested.String(), fmt.Sprintf("%.1f", percent(n.CPURequested, n.CPUAllocatable)),
+			n.MemAllocatable.String(), n.MemRequested.String(), fmt.Sprintf("%.1f", percent(n.MemRequested, n.MemAllocatable)),
+			strings.Join(n.Pressure, ","), strings.Join(n.Taints, ","), n.LocalPVs, n.LocalPVCapacity.String(), n.Pods, n.Age.String(),
+		}, -1)
+	}
+	if err := file.Save(filePath); err != nil {
+		return err
+	}
+	fmt.Print(i18n.T("node.file_written", filePath))
+	return nil
+}
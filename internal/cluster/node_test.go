@@ -0,0 +1,66 @@
+package cluster
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestNodeInfos(t *testing.T) {
+	requests := func(cpu, mem string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem),
+		}}
+	}
+	snap := &Snapshot{
+		Nodes: []corev1.Node{{
+			ObjectMeta: metaV1.ObjectMeta{Name: "n1", Labels: map[string]string{nodeRolePrefix + "worker": ""}},
+			Spec:       corev1.NodeSpec{Unschedulable: true, Taints: []corev1.Taint{{Key: "dedicated", Value: "db", Effect: corev1.TaintEffectNoSchedule}}},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("8Gi")},
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+					{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue},
+					{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				},
+			},
+		}},
+		Pods: []corev1.Pod{
+			{Spec: corev1.PodSpec{NodeName: "n1",
+				// init 容器请求大于业务容器之和时按 init 容器计算
+				InitContainers: []corev1.Container{{Resources: requests("2", "1Gi")}},
+				Containers:     []corev1.Container{{Resources: requests("500m", "1Gi")}, {Resources: requests("500m", "1Gi")}},
+			}},
+			{Spec: corev1.PodSpec{NodeName: "n1", Containers: []corev1.Container{{Resources: requests("1", "2Gi")}}},
+				Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+		},
+		PersistentVolumes: []corev1.PersistentVolume{{
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:               corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+				PersistentVolumeSource: corev1.PersistentVolumeSource{Local: &corev1.LocalVolumeSource{Path: "/data"}},
+				NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "kubernetes.io/hostname", Values: []string{"n1"}}},
+				}}}},
+			},
+		}},
+	}
+	snap.buildIndexes()
+	infos := snap.NodeInfos()
+	if len(infos) != 1 {
+		t.Fatalf("NodeInfos() = %+v", infos)
+	}
+	n := infos[0]
+	if n.Status != "Ready,SchedulingDisabled" || joinOrDash(n.Roles) != "worker" || joinOrDash(n.Taints) != "dedicated=db:NoSchedule" {
+		t.Errorf("node = %+v", n)
+	}
+	if joinOrDash(n.Pressure) != "DiskPressure" {
+		t.Errorf("Pressure = %v", n.Pressure)
+	}
+	if n.Pods != 1 || n.CPURequested.String() != "2" || n.MemRequested.String() != "2Gi" {
+		t.Errorf("requests = %d pods, cpu %s, memory %s", n.Pods, n.CPURequested.String(), n.MemRequested.String())
+	}
+	if n.LocalPVs != 1 || n.LocalPVCapacity.String() != "100Gi" {
+		t.Errorf("local PVs = %d (%s)", n.LocalPVs, n.LocalPVCapacity.String())
+	}
+}
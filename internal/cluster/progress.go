@@ -0,0 +1,63 @@
+package cluster
+
+import (
+	"devops_tools/internal/i18n"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// progress 周期性输出任务进度计数
+type progress struct {
+	label   string
+	total   int64
+	done    atomic.Int64
+	failed  atomic.Int64
+	out     io.Writer
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newProgress(out io.Writer, label string, total int, interval time.Duration) *progress {
+	p := &progress{
+		label:   label,
+		total:   int64(total),
+		out:     out,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.print()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// finish 记录一个任务的完成情况
+func (p *progress) finish(err error) {
+	p.done.Add(1)
+	if err != nil {
+		p.failed.Add(1)
+	}
+}
+
+// close 停止定时输出并打印最终结果
+func (p *progress) close() {
+	close(p.stop)
+	<-p.stopped
+	p.print()
+}
+
+func (p *progress) print() {
+	fmt.Fprint(p.out, i18n.T("progress", p.label, p.done.Load(), p.total, p.failed.Load()))
+}
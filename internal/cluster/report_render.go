@@ -0,0 +1,127 @@
+package cluster
+
+import (
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var reportTemplates embed.FS
+
+// ReportPV 报表中的一个 PV
+type ReportPV struct {
+	PVInfo
+	Orphan bool
+}
+
+// ReportOrphan 报表中的一个孤儿候选
+type ReportOrphan struct {
+	PV     PVInfo
+	Reason CleanupReason
+}
+
+// ReportSection 报表中按 StorageClass 分组的一节
+type ReportSection struct {
+	StorageClassInfo
+	PVs           []ReportPV
+	CapacityBytes int64
+}
+
+// StorageReportData 渲染 HTML/Markdown 报表的数据，自定义模板可以引用这里的全部字段
+type StorageReportData struct {
+	GeneratedAt        time.Time
+	TotalCapacityBytes int64
+	PVCount            int
+	// StaleLocalPVs 绑定节点已不存在的 local PV 数量
+	StaleLocalPVs  int
+	Orphans        []ReportOrphan
+	StorageClasses []ReportSection
+}
+
+// BuildStorageReportData 从快照汇总报表数据
+func BuildStorageReportData(snap *Snapshot) *StorageReportData {
+	data := &StorageReportData{GeneratedAt: time.Now()}
+	pvInfos := snap.PersistentVolumeInfos()
+	pvByName := make(map[string]PVInfo, len(pvInfos))
+	for _, pv := range pvInfos {
+		pvByName[pv.Name] = pv
+	}
+	orphanByPV := make(map[string]bool)
+	for _, c := range snap.OrphanCandidates() {
+		orphanByPV[c.Name] = true
+		data.Orphans = append(data.Orphans, ReportOrphan{PV: pvByName[c.Name], Reason: c.Reason})
+	}
+
+	sections := make(map[string]*ReportSection)
+	for _, sc := range snap.StorageClassInfos() {
+		sections[sc.Name] = &ReportSection{StorageClassInfo: sc}
+	}
+	for _, pv := range pvInfos {
+		data.PVCount++
+		data.TotalCapacityBytes += pv.CapacityBytes
+		if pv.NodeIsExist == "no" {
+			data.StaleLocalPVs++
+		}
+		section, ok := sections[pv.StorageClass]
+		if !ok {
+			section = &ReportSection{StorageClassInfo: StorageClassInfo{Name: pv.StorageClass}}
+			sections[pv.StorageClass] = section
+		}
+		section.PVs = append(section.PVs, ReportPV{PVInfo: pv, Orphan: orphanByPV[pv.Name]})
+		section.CapacityBytes += pv.CapacityBytes
+	}
+	for _, name := range sortedKeys(sections) {
+		data.StorageClasses = append(data.StorageClasses, *sections[name])
+	}
+	return data
+}
+
+// RenderStorageReport 以 html 或 markdown 格式渲染报表，templatePath 非空时使用自定义模板
+func RenderStorageReport(snap *Snapshot, format, templatePath string, w io.Writer) error {
+	funcs := map[string]interface{}{
+		"gib": func(bytes int64) string { return fmt.Sprintf("%.2f", bytesToGiB(bytes)) },
+		// seconds 供 HTML 表格排序使用，避免按 "1h2m3s" 文本比较
+		"seconds": func(d time.Duration) int64 { return int64(d / time.Second) },
+	}
+	var name string
+	switch format {
+	case "html":
+		name = "storage_report.html.tmpl"
+	case "markdown", "md":
+		name = "storage_report.md.tmpl"
+	default:
+		return apperr.ValidationError(i18n.Errorf("report.unsupported", format))
+	}
+
+	content, err := reportTemplates.ReadFile("templates/" + name)
+	if templatePath != "" {
+		name = filepath.Base(templatePath)
+		content, err = os.ReadFile(templatePath)
+	}
+	if err != nil {
+		return i18n.Errorf("report.template_read", err)
+	}
+
+	data := BuildStorageReportData(snap)
+	if format == "html" {
+		tmpl, err := htmltemplate.New(name).Funcs(funcs).Parse(string(content))
+		if err != nil {
+			return i18n.Errorf("report.template_parse", err)
+		}
+		return tmpl.Execute(w, data)
+	}
+	// Markdown 不需要 HTML 转义
+	tmpl, err := texttemplate.New(name).Funcs(funcs).Parse(string(content))
+	if err != nil {
+		return i18n.Errorf("report.template_parse", err)
+	}
+	return tmpl.Execute(w, data)
+}
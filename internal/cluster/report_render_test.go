@@ -0,0 +1,70 @@
+package cluster
+
+import (
+	"bytes"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"testing"
+	"time"
+)
+
+func reportSnapshot() *Snapshot {
+	orphan := testPV("pv-orphan", "Available", "1Gi")
+	orphan.Spec.StorageClassName = "local"
+	orphan.CreationTimestamp = metaV1.NewTime(time.Now().Add(-2 * time.Hour))
+	bound := testPV("pv-<bound>", "Bound", "2Gi")
+	bound.Spec.StorageClassName = "local"
+	bound.CreationTimestamp = metaV1.NewTime(time.Now().Add(-90 * time.Second))
+	snap := &Snapshot{
+		StorageClasses:    []storagev1.StorageClass{{ObjectMeta: metaV1.ObjectMeta{Name: "local"}, Provisioner: "kubernetes.io/no-provisioner"}},
+		PersistentVolumes: []corev1.PersistentVolume{orphan, bound},
+	}
+	snap.buildIndexes()
+	return snap
+}
+
+func TestRenderStorageReportHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderStorageReport(reportSnapshot(), "html", "", &buf); err != nil {
+		t.Fatalf("RenderStorageReport(html) error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<tr class="orphan"><td>pv-orphan</td><td>Available</td><td>local</td><td>1.00</td>`,
+		`<div class="value">3.00</div>`,
+		// AGE 列按秒数排序
+		`<td data-sort="7200">2h0m0s</td>`,
+		`<td data-sort="90">1m30s</td>`,
+		// HTML 模板会转义资源名称
+		`<td>pv-&lt;bound&gt;</td>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html report missing %q", want)
+		}
+	}
+}
+
+func TestRenderStorageReportMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderStorageReport(reportSnapshot(), "md", "", &buf); err != nil {
+		t.Fatalf("RenderStorageReport(md) error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"| 3.00 | 2 | 1 | 0 |",
+		"| pv-orphan | Available | local | 1.00 |  |",
+		"## local",
+		"| pv-orphan ⚠ | 1.00 | Available |",
+		"| pv-<bound> | 2.00 | Bound |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown report missing %q\n%s", want, out)
+		}
+	}
+
+	if err := RenderStorageReport(reportSnapshot(), "pdf", "", &buf); err == nil {
+		t.Error("RenderStorageReport(pdf) should fail")
+	}
+}
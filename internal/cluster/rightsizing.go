@@ -0,0 +1,314 @@
+package cluster
+
+import (
+	"context"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
+	"fmt"
+	"github.com/tealeg/xlsx/v3"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// 推荐值的下限，避免给空闲容器推荐过小的请求
+const (
+	minCPUMilli = 10
+	minMemory   = 16 << 20
+)
+
+// ContainerUsage 一次采样中一个容器的用量
+type ContainerUsage struct {
+	CPUMilli    int64
+	MemoryBytes int64
+}
+
+// UsageSamples 多次采样的容器用量，按 namespace/pod 和容器名称索引
+type UsageSamples struct {
+	Count int
+	Pods  map[string]map[string][]ContainerUsage
+}
+
+func (u *UsageSamples) add(namespace, pod, container string, usage ContainerUsage) {
+	key := namespace + "/" + pod
+	if u.Pods[key] == nil {
+		u.Pods[key] = make(map[string][]ContainerUsage)
+	}
+	u.Pods[key][container] = append(u.Pods[key][container], usage)
+}
+
+// Unmatched 返回有采样但不在快照中的 Pod，这些 Pod 的用量不会计入 Rightsizing 的推荐值
+func (u *UsageSamples) Unmatched(snap *Snapshot) []string {
+	pods := make(map[string]bool, len(snap.Pods))
+	for i := range snap.Pods {
+		pods[snap.Pods[i].Namespace+"/"+snap.Pods[i].Name] = true
+	}
+	var unmatched []string
+	for key := range u.Pods {
+		if !pods[key] {
+			unmatched = append(unmatched, key)
+		}
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
+
+// CollectUsage 从 metrics.k8s.io 读取 Pod 用量。window 为 0 时只采样一次，
+// 否则在 window 内每隔 interval 采样一次，每次采样后向 progress 输出进度
+func CollectUsage(ctx context.Context, client metricsclient.Interface, namespace string, window, interval time.Duration, progress io.Writer) (*UsageSamples, error) {
+	samples := &UsageSamples{Pods: make(map[string]map[string][]ContainerUsage)}
+	total := 1
+	if window > 0 && interval > 0 {
+		total = int(window/interval) + 1
+	}
+	for i := 0; i < total; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+		list, err := client.MetricsV1beta1().PodMetricses(namespace).List(ctx, metaV1.ListOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, apperr.ConnectionError(i18n.Errorf("rightsizing.metrics_unavailable", err))
+			}
+			return nil, err
+		}
+		for _, pm := range list.Items {
+			for _, c := range pm.Containers {
+				samples.add(pm.Namespace, pm.Name, c.Name, ContainerUsage{
+					CPUMilli:    c.Usage.Cpu().MilliValue(),
+					MemoryBytes: c.Usage.Memory().Value(),
+				})
+			}
+		}
+		samples.Count++
+		if total > 1 {
+			fmt.Fprint(progress, i18n.T("rightsizing.sampled", samples.Count, total))
+		}
+	}
+	return samples, nil
+}
+
+// RightsizingOptions rightsizing 的参数
+type RightsizingOptions struct {
+	Namespace string
+	// Headroom 推荐值在观测用量之上预留的比例，0.2 表示 20%
+	Headroom float64
+}
+
+// RightsizingInfo 一个工作负载中一个容器的当前配置、观测用量和推荐值
+type RightsizingInfo struct {
+	Namespace string
+	Kind      string
+	Workload  string
+	Container string
+	Pods      int
+	Samples   int
+	Requests  corev1.ResourceList
+	Limits    corev1.ResourceList
+	// CPU 为所有 Pod 全部采样的平均值和 P95，内存为平均值和峰值
+	CPUAvg     resource.Quantity
+	CPUP95     resource.Quantity
+	MemoryAvg  resource.Quantity
+	MemoryPeak resource.Quantity
+	// Recommended 推荐的 requests 和 limits，当前未设置 CPU limit 时不推荐 CPU limit
+	RecommendedRequests corev1.ResourceList
+	RecommendedLimits   corev1.ResourceList
+}
+
+// Rightsizing 按工作负载汇总容器用量并计算推荐值。不属于任何工作负载的 Pod 以 Kind Pod 单独列出，
+// 没有采样数据的容器不出现在结果中。采样按 namespace/pod 匹配快照中的 Pod，
+// 快照时已删除的 Pod（例如窗口内滚动更新替换掉的 Pod）的采样不计入，见 UsageSamples.Unmatched
+func (s *Snapshot) Rightsizing(samples *UsageSamples, opts RightsizingOptions) []RightsizingInfo {
+	type group struct {
+		kind, namespace, name string
+		spec                  *corev1.PodSpec
+		pods                  []string
+	}
+	var groups []group
+	owned := make(map[string]bool)
+	for _, w := range s.workloads() {
+		g := group{kind: w.kind, namespace: w.meta.Namespace, name: w.meta.Name, spec: w.spec}
+		for _, pod := range w.pods {
+			key := pod.Namespace + "/" + pod.Name
+			g.pods = append(g.pods, key)
+			owned[key] = true
+		}
+		groups = append(groups, g)
+	}
+	for i := range s.Pods {
+		pod := &s.Pods[i]
+		if key := pod.Namespace + "/" + pod.Name; !owned[key] {
+			groups = append(groups, group{kind: "Pod", namespace: pod.Namespace, name: pod.Name, spec: &pod.Spec, pods: []string{key}})
+		}
+	}
+
+	var infos []RightsizingInfo
+	for _, g := range groups {
+		if opts.Namespace != "" && g.namespace != opts.Namespace {
+			continue
+		}
+		for _, c := range g.spec.Containers {
+			var usage []ContainerUsage
+			pods := 0
+			for _, key := range g.pods {
+				if u := samples.Pods[key][c.Name]; len(u) > 0 {
+					usage = append(usage, u...)
+					pods++
+				}
+			}
+			if len(usage) == 0 {
+				continue
+			}
+			infos = append(infos, recommend(g.kind, g.namespace, g.name, c, pods, usage, opts.Headroom))
+		}
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		a, b := infos[i], infos[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Workload != b.Workload {
+			return a.Workload < b.Workload
+		}
+		return a.Container < b.Container
+	})
+	return infos
+}
+
+// recommend CPU 请求取 P95，内存请求取峰值，均加上 headroom；
+// 内存 limit 为峰值加两倍 headroom 且不低于内存请求，CPU limit 仅在当前已设置时推荐为请求的两倍
+func recommend(kind, namespace, workload string, c corev1.Container, pods int, usage []ContainerUsage, headroom float64) RightsizingInfo {
+	cpu := make([]int64, len(usage))
+	var cpuSum, memSum, memPeak int64
+	for i, u := range usage {
+		cpu[i] = u.CPUMilli
+		cpuSum += u.CPUMilli
+		memSum += u.MemoryBytes
+		if u.MemoryBytes > memPeak {
+			memPeak = u.MemoryBytes
+		}
+	}
+	sort.Slice(cpu, func(i, j int) bool { return cpu[i] < cpu[j] })
+	p95 := cpu[int(math.Ceil(float64(len(cpu))*0.95))-1]
+	n := int64(len(usage))
+
+	cpuRequest := roundUp(max(int64(float64(p95)*(1+headroom)), minCPUMilli), 5)
+	memRequest := roundUp(max(int64(float64(memPeak)*(1+headroom)), minMemory), 1<<20)
+	// 请求按下限取整后可能大于按峰值计算的 limit，limit 不能低于请求
+	memLimit := max(roundUp(int64(float64(memPeak)*(1+2*headroom)), 1<<20), memRequest)
+	info := RightsizingInfo{
+		Namespace:           namespace,
+		Kind:                kind,
+		Workload:            workload,
+		Container:           c.Name,
+		Pods:                pods,
+		Samples:             len(usage),
+		Requests:            c.Resources.Requests,
+		Limits:              c.Resources.Limits,
+		CPUAvg:              *resource.NewMilliQuantity(cpuSum/n, resource.DecimalSI),
+		CPUP95:              *resource.NewMilliQuantity(p95, resource.DecimalSI),
+		MemoryAvg:           *resource.NewQuantity(roundUp(memSum/n, 1<<20), resource.BinarySI),
+		MemoryPeak:          *resource.NewQuantity(roundUp(memPeak, 1<<20), resource.BinarySI),
+		RecommendedRequests: corev1.ResourceList{corev1.ResourceCPU: *resource.NewMilliQuantity(cpuRequest, resource.DecimalSI), corev1.ResourceMemory: *resource.NewQuantity(memRequest, resource.BinarySI)},
+		RecommendedLimits:   corev1.ResourceList{corev1.ResourceMemory: *resource.NewQuantity(memLimit, resource.BinarySI)},
+	}
+	if _, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
+		info.RecommendedLimits[corev1.ResourceCPU] = *resource.NewMilliQuantity(cpuRequest*2, resource.DecimalSI)
+	}
+	return info
+}
+
+// roundUp 向上取整到 step 的倍数
+func roundUp(v, step int64) int64 {
+	return (v + step - 1) / step * step
+}
+
+// cpuSaving 当前 CPU 请求比推荐值多出的部分，未设置请求或不足时为 0
+func (r RightsizingInfo) cpuSaving() int64 {
+	current := r.Requests[corev1.ResourceCPU]
+	rec := r.RecommendedRequests[corev1.ResourceCPU]
+	return max(current.MilliValue()-rec.MilliValue(), 0) * int64(r.Pods)
+}
+
+// memorySaving 当前内存请求比推荐值多出的部分，按 Pod 数累计
+func (r RightsizingInfo) memorySaving() int64 {
+	current := r.Requests[corev1.ResourceMemory]
+	rec := r.RecommendedRequests[corev1.ResourceMemory]
+	return max(current.Value()-rec.Value(), 0) * int64(r.Pods)
+}
+
+func quantityOrDash(list corev1.ResourceList, name corev1.ResourceName) string {
+	if q, ok := list[name]; ok {
+		return q.String()
+	}
+	return "-"
+}
+
+// PrintRightsizing 输出推荐结果，filePath 非空时写入 Excel。
+// 表格末尾汇总按推荐值调整后可释放的 CPU 和内存请求
+func PrintRightsizing(infos []RightsizingInfo, filePath string) error {
+	var cpuSaving, memorySaving int64
+	for _, r := range infos {
+		cpuSaving += r.cpuSaving()
+		memorySaving += r.memorySaving()
+	}
+	if filePath == "" {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, i18n.Header("NAMESPACE", "KIND", "WORKLOAD", "CONTAINER", "PODS",
+			"CPU REQ/LIMIT", "CPU AVG/P95", "REC CPU REQ/LIMIT", "MEM REQ/LIMIT", "MEM AVG/PEAK", "REC MEM REQ/LIMIT"))
+		for _, r := range infos {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s/%s\t%s/%s\t%s/%s\t%s/%s\t%s/%s\t%s/%s\n",
+				r.Namespace, r.Kind, r.Workload, r.Container, r.Pods,
+				quantityOrDash(r.Requests, corev1.ResourceCPU), quantityOrDash(r.Limits, corev1.ResourceCPU),
+				r.CPUAvg.String(), r.CPUP95.String(),
+				quantityOrDash(r.RecommendedRequests, corev1.ResourceCPU), quantityOrDash(r.RecommendedLimits, corev1.ResourceCPU),
+				quantityOrDash(r.Requests, corev1.ResourceMemory), quantityOrDash(r.Limits, corev1.ResourceMemory),
+				r.MemoryAvg.String(), r.MemoryPeak.String(),
+				quantityOrDash(r.RecommendedRequests, corev1.ResourceMemory), quantityOrDash(r.RecommendedLimits, corev1.ResourceMemory))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Print(i18n.T("rightsizing.saving", resource.NewMilliQuantity(cpuSaving, resource.DecimalSI).String(),
+			resource.NewQuantity(memorySaving, resource.BinarySI).String()))
+		return nil
+	}
+
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("Rightsizing")
+	if err != nil {
+		return err
+	}
+	sheet.AddRow().WriteSlice([]string{
+		"NAMESPACE", "KIND", "WORKLOAD", "CONTAINER", "PODS", "SAMPLES",
+		"CPU REQUEST", "CPU LIMIT", "CPU AVG", "CPU P95", "RECOMMENDED CPU REQUEST", "RECOMMENDED CPU LIMIT", "CPU SAVING (m)",
+		"MEMORY REQUEST", "MEMORY LIMIT", "MEMORY AVG", "MEMORY PEAK", "RECOMMENDED MEMORY REQUEST", "RECOMMENDED MEMORY LIMIT", "MEMORY SAVING (Mi)",
+	}, -1)
+	for _, r := range infos {
+		sheet.AddRow().WriteSlice([]interface{}{
+			r.Namespace, r.Kind, r.Workload, r.Container, r.Pods, r.Samples,
+			quantityOrDash(r.Requests, corev1.ResourceCPU), quantityOrDash(r.Limits, corev1.ResourceCPU), r.CPUAvg.String(), r.CPUP95.String(),
+			quantityOrDash(r.RecommendedRequests, corev1.ResourceCPU), quantityOrDash(r.RecommendedLimits, corev1.ResourceCPU), r.cpuSaving(),
+			quantityOrDash(r.Requests, corev1.ResourceMemory), quantityOrDash(r.Limits, corev1.ResourceMemory), r.MemoryAvg.String(), r.MemoryPeak.String(),
+			quantityOrDash(r.RecommendedRequests, corev1.ResourceMemory), quantityOrDash(r.RecommendedLimits, corev1.ResourceMemory), r.memorySaving() >> 20,
+		}, -1)
+	}
+	if err := file.Save(filePath); err != nil {
+		return err
+	}
+	fmt.Print(i18n.T("rightsizing.file_written", filePath))
+	return nil
+}
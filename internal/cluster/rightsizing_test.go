@@ -0,0 +1,76 @@
+package cluster
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestRightsizing(t *testing.T) {
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+	}}}}}
+	snap := &Snapshot{
+		Deployments: []appsv1.Deployment{{
+			ObjectMeta: metaV1.ObjectMeta{Name: "web", Namespace: "ns"},
+			Spec:       appsv1.DeploymentSpec{Selector: &metaV1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}, Template: template},
+		}},
+		Pods: []corev1.Pod{
+			{ObjectMeta: metaV1.ObjectMeta{Name: "web-1", Namespace: "ns", Labels: map[string]string{"app": "web"}}, Spec: template.Spec},
+			{ObjectMeta: metaV1.ObjectMeta{Name: "web-2", Namespace: "ns", Labels: map[string]string{"app": "web"}}, Spec: template.Spec},
+			{ObjectMeta: metaV1.ObjectMeta{Name: "debug", Namespace: "ns"}, Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "sh"}}}},
+		},
+	}
+	snap.buildIndexes()
+	samples := &UsageSamples{Count: 2, Pods: make(map[string]map[string][]ContainerUsage)}
+	samples.add("ns", "web-1", "app", ContainerUsage{CPUMilli: 100, MemoryBytes: 200 << 20})
+	samples.add("ns", "web-1", "app", ContainerUsage{CPUMilli: 300, MemoryBytes: 300 << 20})
+	samples.add("ns", "web-2", "app", ContainerUsage{CPUMilli: 200, MemoryBytes: 250 << 20})
+	samples.add("ns", "debug", "sh", ContainerUsage{CPUMilli: 1, MemoryBytes: 1 << 20})
+	// 快照中已不存在的 Pod 的采样不计入
+	samples.add("ns", "web-old", "app", ContainerUsage{CPUMilli: 5000, MemoryBytes: 4 << 30})
+	if got := samples.Unmatched(snap); len(got) != 1 || got[0] != "ns/web-old" {
+		t.Errorf("Unmatched() = %v", got)
+	}
+
+	infos := snap.Rightsizing(samples, RightsizingOptions{Headroom: 0.2})
+	if len(infos) != 2 || infos[0].Kind != "Pod" || infos[1].Workload != "web" {
+		t.Fatalf("Rightsizing() = %+v", infos)
+	}
+	web := infos[1]
+	if web.Pods != 2 || web.Samples != 3 || web.CPUP95.String() != "300m" || web.MemoryPeak.String() != "300Mi" {
+		t.Errorf("web usage = %+v", web)
+	}
+	for _, tc := range []struct {
+		list corev1.ResourceList
+		name corev1.ResourceName
+		want string
+	}{
+		{web.RecommendedRequests, corev1.ResourceCPU, "360m"},
+		{web.RecommendedRequests, corev1.ResourceMemory, "360Mi"},
+		{web.RecommendedLimits, corev1.ResourceCPU, "720m"},
+		{web.RecommendedLimits, corev1.ResourceMemory, "420Mi"},
+	} {
+		if got := quantityOrDash(tc.list, tc.name); got != tc.want {
+			t.Errorf("recommended %s = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+	// 空闲容器按下限推荐，未设置 CPU limit 时不推荐
+	debug := infos[0]
+	if got := quantityOrDash(debug.RecommendedRequests, corev1.ResourceCPU); got != "10m" {
+		t.Errorf("debug cpu request = %s", got)
+	}
+	if got := quantityOrDash(debug.RecommendedLimits, corev1.ResourceCPU); got != "-" {
+		t.Errorf("debug cpu limit = %s", got)
+	}
+	// 1Mi 峰值的内存请求按下限取 16Mi，limit 不能低于请求
+	if req, limit := quantityOrDash(debug.RecommendedRequests, corev1.ResourceMemory), quantityOrDash(debug.RecommendedLimits, corev1.ResourceMemory); req != "16Mi" || limit != "16Mi" {
+		t.Errorf("debug memory request/limit = %s/%s, want 16Mi/16Mi", req, limit)
+	}
+	if got := web.cpuSaving(); got != 2*(1000-360) {
+		t.Errorf("cpuSaving() = %d", got)
+	}
+}
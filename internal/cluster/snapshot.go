@@ -0,0 +1,173 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	bv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/client-go/kubernetes"
+	"strings"
+	"sync"
+)
+
+// Snapshot 集群存储相关资源的一次性快照，所有 cluster 子命令都从这里读取数据
+type Snapshot struct {
+	StorageClasses         []storagev1.StorageClass
+	Namespaces             []corev1.Namespace
+	Nodes                  []corev1.Node
+	PersistentVolumes      []corev1.PersistentVolume
+	PersistentVolumeClaims []corev1.PersistentVolumeClaim
+	Pods                   []corev1.Pod
+	Deployments            []appsv1.Deployment
+	DaemonSets             []appsv1.DaemonSet
+	StatefulSets           []appsv1.StatefulSet
+	CronJobs               []bv1.CronJob
+	Jobs                   []bv1.Job
+	ResourceQuotas         []corev1.ResourceQuota
+
+	nodeByName     map[string]*corev1.Node
+	pvcByKey       map[string]*corev1.PersistentVolumeClaim
+	podsByPVC      map[string][]*corev1.Pod
+	localPVsByNode map[string][]*corev1.PersistentVolume
+	pvsBySC        map[string][]*corev1.PersistentVolume
+	nsBySC         map[string][]string
+}
+
+// StorageAnnotation 命名空间上声明可用 StorageClass 的注解，多个值以逗号分隔
+const StorageAnnotation = "dophin/storage"
+
+// LoadSnapshot 并行分页拉取所有资源并建立索引
+func LoadSnapshot(ctx context.Context, client kubernetes.Interface) (*Snapshot, error) {
+	s := &Snapshot{}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	load := func(kind string, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("list %s: %w", kind, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	load("storageclasses", func() (err error) { s.StorageClasses, err = listStorageClasses(ctx, client); return })
+	load("namespaces", func() (err error) { s.Namespaces, err = listNamespaces(ctx, client); return })
+	load("nodes", func() (err error) { s.Nodes, err = listNodes(ctx, client); return })
+	load("persistentvolumes", func() (err error) { s.PersistentVolumes, err = listPersistentVolumes(ctx, client); return })
+	load("persistentvolumeclaims", func() (err error) { s.PersistentVolumeClaims, err = listPersistentVolumeClaims(ctx, client); return })
+	load("pods", func() (err error) { s.Pods, err = listPods(ctx, client); return })
+	load("deployments", func() (err error) { s.Deployments, err = listDeployments(ctx, client); return })
+	load("daemonsets", func() (err error) { s.DaemonSets, err = listDaemonSets(ctx, client); return })
+	load("statefulsets", func() (err error) { s.StatefulSets, err = listStatefulSets(ctx, client); return })
+	load("cronjobs", func() (err error) { s.CronJobs, err = listCronJobs(ctx, client); return })
+	load("jobs", func() (err error) { s.Jobs, err = listJobs(ctx, client); return })
+	load("resourcequotas", func() (err error) { s.ResourceQuotas, err = listResourceQuotas(ctx, client); return })
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	s.buildIndexes()
+	return s, nil
+}
+
+// buildIndexes 根据已加载的资源重建索引
+func (s *Snapshot) buildIndexes() {
+	s.nodeByName = make(map[string]*corev1.Node, len(s.Nodes))
+	for i := range s.Nodes {
+		s.nodeByName[s.Nodes[i].Name] = &s.Nodes[i]
+	}
+	s.pvcByKey = make(map[string]*corev1.PersistentVolumeClaim, len(s.PersistentVolumeClaims))
+	for i := range s.PersistentVolumeClaims {
+		pvc := &s.PersistentVolumeClaims[i]
+		s.pvcByKey[pvc.Namespace+"/"+pvc.Name] = pvc
+	}
+	s.podsByPVC = make(map[string][]*corev1.Pod)
+	for i := range s.Pods {
+		pod := &s.Pods[i]
+		for _, v := range pod.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil {
+				key := pod.Namespace + "/" + v.PersistentVolumeClaim.ClaimName
+				s.podsByPVC[key] = append(s.podsByPVC[key], pod)
+			}
+		}
+	}
+	s.nsBySC = make(map[string][]string)
+	for _, ns := range s.Namespaces {
+		for _, sc := range strings.Split(ns.Annotations[StorageAnnotation], ",") {
+			if sc != "" {
+				s.nsBySC[sc] = append(s.nsBySC[sc], ns.Name)
+			}
+		}
+	}
+	s.localPVsByNode = make(map[string][]*corev1.PersistentVolume)
+	s.pvsBySC = make(map[string][]*corev1.PersistentVolume)
+	for i := range s.PersistentVolumes {
+		pv := &s.PersistentVolumes[i]
+		if pv.Spec.StorageClassName != "" {
+			s.pvsBySC[pv.Spec.StorageClassName] = append(s.pvsBySC[pv.Spec.StorageClassName], pv)
+		}
+		if pv.Spec.Local != nil {
+			for _, node := range localPVNodes(pv) {
+				s.localPVsByNode[node] = append(s.localPVsByNode[node], pv)
+			}
+		}
+	}
+}
+
+// NodeExists 判断节点是否存在
+func (s *Snapshot) NodeExists(name string) bool {
+	_, ok := s.nodeByName[name]
+	return ok
+}
+
+// PVC 按命名空间和名称查找 PVC
+func (s *Snapshot) PVC(namespace, name string) (*corev1.PersistentVolumeClaim, bool) {
+	pvc, ok := s.pvcByKey[namespace+"/"+name]
+	return pvc, ok
+}
+
+// PodsUsingPVC 返回挂载了指定 PVC 的 Pod
+func (s *Snapshot) PodsUsingPVC(namespace, name string) []*corev1.Pod {
+	return s.podsByPVC[namespace+"/"+name]
+}
+
+// LocalPVsOnNode 返回通过 NodeAffinity 绑定到指定节点的 local PV
+func (s *Snapshot) LocalPVsOnNode(node string) []*corev1.PersistentVolume {
+	return s.localPVsByNode[node]
+}
+
+// PVsByStorageClass 返回使用指定 StorageClass 的 PV
+func (s *Snapshot) PVsByStorageClass(sc string) []*corev1.PersistentVolume {
+	return s.pvsBySC[sc]
+}
+
+// NamespacesBoundTo 返回通过 dophin/storage 注解绑定了指定 StorageClass 的命名空间
+func (s *Snapshot) NamespacesBoundTo(sc string) []string {
+	return s.nsBySC[sc]
+}
+
+// localPVNodes 从 local PV 的 NodeAffinity 中解析绑定的节点
+func localPVNodes(pv *corev1.PersistentVolume) []string {
+	var nodes []string
+	affinity := pv.Spec.NodeAffinity
+	if affinity == nil || affinity.Required == nil {
+		return nil
+	}
+	for _, t := range affinity.Required.NodeSelectorTerms {
+		for _, req := range t.MatchExpressions {
+			if req.Key == "kubernetes.io/hostname" && len(req.Values) > 0 {
+				nodes = append(nodes, req.Values...)
+			}
+		}
+	}
+	return nodes
+}
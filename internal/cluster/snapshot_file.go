@@ -0,0 +1,225 @@
+package cluster
+
+import (
+	"devops_tools/internal/i18n"
+	"errors"
+	"io"
+	appsv1 "k8s.io/api/apps/v1"
+	bv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadSnapshotFromPath 从 kubectl get -o yaml/json 导出的文件或目录加载快照，
+// 支持 List、单个对象以及多文档 YAML，未识别的资源类型会被忽略
+func LoadSnapshotFromPath(path string) (*Snapshot, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".yaml", ".yml", ".json":
+				if !d.IsDir() {
+					files = append(files, p)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Snapshot{}
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	for _, f := range files {
+		if err := s.loadFile(decoder, f); err != nil {
+			return nil, i18n.Errorf("snapshot.load_failed", f, err)
+		}
+	}
+	s.buildIndexes()
+	return s, nil
+}
+
+func (s *Snapshot) loadFile(decoder runtime.Decoder, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	d := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var raw runtime.RawExtension
+		if err := d.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if len(raw.Raw) == 0 || string(raw.Raw) == "null" {
+			continue
+		}
+		if err := s.addRaw(decoder, raw.Raw); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Snapshot) addRaw(decoder runtime.Decoder, raw []byte) error {
+	obj, _, err := decoder.Decode(raw, nil, nil)
+	if err != nil {
+		if runtime.IsNotRegisteredError(err) {
+			return nil
+		}
+		return err
+	}
+	if list, ok := obj.(*corev1.List); ok {
+		for _, item := range list.Items {
+			if err := s.addRaw(decoder, item.Raw); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if meta.IsListType(obj) {
+		items, err := meta.ExtractList(obj)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			s.add(item)
+		}
+		return nil
+	}
+	s.add(obj)
+	return nil
+}
+
+func (s *Snapshot) add(obj runtime.Object) {
+	switch o := obj.(type) {
+	case *storagev1.StorageClass:
+		s.StorageClasses = append(s.StorageClasses, *o)
+	case *corev1.Namespace:
+		s.Namespaces = append(s.Namespaces, *o)
+	case *corev1.Node:
+		s.Nodes = append(s.Nodes, *o)
+	case *corev1.PersistentVolume:
+		s.PersistentVolumes = append(s.PersistentVolumes, *o)
+	case *corev1.PersistentVolumeClaim:
+		s.PersistentVolumeClaims = append(s.PersistentVolumeClaims, *o)
+	case *corev1.Pod:
+		s.Pods = append(s.Pods, *o)
+	case *appsv1.Deployment:
+		s.Deployments = append(s.Deployments, *o)
+	case *appsv1.DaemonSet:
+		s.DaemonSets = append(s.DaemonSets, *o)
+	case *appsv1.StatefulSet:
+		s.StatefulSets = append(s.StatefulSets, *o)
+	case *bv1.CronJob:
+		s.CronJobs = append(s.CronJobs, *o)
+	case *bv1.Job:
+		s.Jobs = append(s.Jobs, *o)
+	case *corev1.ResourceQuota:
+		s.ResourceQuotas = append(s.ResourceQuotas, *o)
+	}
+}
+
+// SaveSnapshot 将快照按资源类型写入目录，每个文件是一个 kind: List，
+// 格式与 kubectl get -o yaml 一致，可以被 LoadSnapshotFromPath 重新加载
+func SaveSnapshot(snap *Snapshot, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return i18n.Errorf("snapshot.mkdir_failed", err)
+	}
+	files := []struct {
+		name    string
+		objects []runtime.Object
+	}{
+		{"storageclasses.yaml", toObjects(snap.StorageClasses)},
+		{"namespaces.yaml", toObjects(snap.Namespaces)},
+		{"nodes.yaml", toObjects(snap.Nodes)},
+		{"persistentvolumes.yaml", toObjects(snap.PersistentVolumes)},
+		{"persistentvolumeclaims.yaml", toObjects(snap.PersistentVolumeClaims)},
+		{"pods.yaml", toObjects(snap.Pods)},
+		{"deployments.yaml", toObjects(snap.Deployments)},
+		{"daemonsets.yaml", toObjects(snap.DaemonSets)},
+		{"statefulsets.yaml", toObjects(snap.StatefulSets)},
+		{"cronjobs.yaml", toObjects(snap.CronJobs)},
+		{"jobs.yaml", toObjects(snap.Jobs)},
+		{"resourcequotas.yaml", toObjects(snap.ResourceQuotas)},
+	}
+	for _, f := range files {
+		if err := writeList(filepath.Join(dir, f.name), f.objects); err != nil {
+			return i18n.Errorf("snapshot.write_failed", f.name, err)
+		}
+	}
+	return nil
+}
+
+func writeList(path string, objects []runtime.Object) error {
+	jsonSerializer := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme, scheme, json.SerializerOptions{})
+	yamlSerializer := json.NewYAMLSerializer(json.DefaultMetaFactory, scheme, scheme)
+
+	list := &corev1.List{}
+	list.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("List"))
+	for _, obj := range objects {
+		if err := setGroupVersionKind(obj); err != nil {
+			return err
+		}
+		if accessor, err := meta.Accessor(obj); err == nil {
+			accessor.SetManagedFields(nil)
+		}
+		raw, err := runtime.Encode(jsonSerializer, obj)
+		if err != nil {
+			return err
+		}
+		list.Items = append(list.Items, runtime.RawExtension{Raw: raw})
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return yamlSerializer.Encode(list, file)
+}
+
+// setGroupVersionKind LIST 返回的对象不带 TypeMeta，从 scheme 中补全 GVK
+func setGroupVersionKind(obj runtime.Object) error {
+	if !obj.GetObjectKind().GroupVersionKind().Empty() {
+		return nil
+	}
+	gvks, _, err := scheme.ObjectKinds(obj)
+	if err != nil || len(gvks) == 0 {
+		return i18n.Errorf("snapshot.gvk_failed", err)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	return nil
+}
+
+// toObjects 将资源切片转换为 runtime.Object 列表
+func toObjects[T any, PT interface {
+	*T
+	runtime.Object
+}](items []T) []runtime.Object {
+	objects := make([]runtime.Object, 0, len(items))
+	for i := range items {
+		objects = append(objects, PT(&items[i]))
+	}
+	return objects
+}
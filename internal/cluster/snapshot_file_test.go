@@ -0,0 +1,90 @@
+package cluster
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	claimed := testPV("pv-2", "Released", "2Gi")
+	claimed.Spec.StorageClassName = "local"
+	claimed.Spec.ClaimRef = &corev1.ObjectReference{Namespace: "ns", Name: "data", UID: "uid-1"}
+	snap := &Snapshot{
+		StorageClasses:         []storagev1.StorageClass{{ObjectMeta: metaV1.ObjectMeta{Name: "local"}, Provisioner: "kubernetes.io/no-provisioner"}},
+		Namespaces:             []corev1.Namespace{{ObjectMeta: metaV1.ObjectMeta{Name: "ns"}}},
+		Nodes:                  []corev1.Node{{ObjectMeta: metaV1.ObjectMeta{Name: "n1"}}},
+		PersistentVolumes:      []corev1.PersistentVolume{testPV("pv-1", "Available", "1Gi"), claimed},
+		PersistentVolumeClaims: []corev1.PersistentVolumeClaim{{ObjectMeta: metaV1.ObjectMeta{Name: "data", Namespace: "ns", UID: "uid-1"}}},
+		Pods:                   []corev1.Pod{{ObjectMeta: metaV1.ObjectMeta{Name: "p1", Namespace: "ns"}, Spec: corev1.PodSpec{Volumes: claimVolume("data")}}},
+	}
+	dir := t.TempDir()
+	if err := SaveSnapshot(snap, dir); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+
+	check := func(name string, got *Snapshot) {
+		t.Helper()
+		if len(got.StorageClasses) != 1 || got.StorageClasses[0].Provisioner != "kubernetes.io/no-provisioner" {
+			t.Errorf("%s: StorageClasses = %+v", name, got.StorageClasses)
+		}
+		if len(got.PersistentVolumes) != 2 {
+			t.Fatalf("%s: got %d PersistentVolumes, want 2", name, len(got.PersistentVolumes))
+		}
+		if ref := got.PersistentVolumes[1].Spec.ClaimRef; ref == nil || ref.UID != "uid-1" {
+			t.Errorf("%s: ClaimRef = %+v", name, ref)
+		}
+		if _, ok := got.PVC("ns", "data"); !ok {
+			t.Errorf("%s: PVC(ns, data) missing, indexes not built", name)
+		}
+		if len(got.PodsUsingPVC("ns", "data")) != 1 {
+			t.Errorf("%s: PodsUsingPVC(ns, data) = %v", name, got.PodsUsingPVC("ns", "data"))
+		}
+	}
+
+	loaded, err := LoadSnapshotFromPath(dir)
+	if err != nil {
+		t.Fatalf("LoadSnapshotFromPath(dir) error = %v", err)
+	}
+	check("directory", loaded)
+	if len(loaded.Namespaces) != 1 || len(loaded.Nodes) != 1 {
+		t.Errorf("directory: Namespaces = %d, Nodes = %d", len(loaded.Namespaces), len(loaded.Nodes))
+	}
+
+	// 单个 YAML 文件只包含其中的资源类型
+	single, err := LoadSnapshotFromPath(filepath.Join(dir, "persistentvolumes.yaml"))
+	if err != nil {
+		t.Fatalf("LoadSnapshotFromPath(file) error = %v", err)
+	}
+	if len(single.PersistentVolumes) != 2 || len(single.StorageClasses) != 0 || len(single.Pods) != 0 {
+		t.Errorf("single file: PVs = %d, SCs = %d, Pods = %d", len(single.PersistentVolumes), len(single.StorageClasses), len(single.Pods))
+	}
+
+	// kubectl get -o json 导出的 List，未注册的资源类型会被忽略
+	listJSON := `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {"apiVersion": "storage.k8s.io/v1", "kind": "StorageClass", "metadata": {"name": "local"}, "provisioner": "kubernetes.io/no-provisioner"},
+    {"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-1"}, "spec": {"capacity": {"storage": "1Gi"}}, "status": {"phase": "Available"}},
+    {"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-2"}, "spec": {"capacity": {"storage": "2Gi"}, "storageClassName": "local",
+      "claimRef": {"namespace": "ns", "name": "data", "uid": "uid-1"}}, "status": {"phase": "Released"}},
+    {"apiVersion": "v1", "kind": "PersistentVolumeClaim", "metadata": {"name": "data", "namespace": "ns", "uid": "uid-1"}},
+    {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "p1", "namespace": "ns"},
+      "spec": {"containers": [], "volumes": [{"name": "data", "persistentVolumeClaim": {"claimName": "data"}}]}},
+    {"apiVersion": "example.com/v1", "kind": "Unknown", "metadata": {"name": "x"}}
+  ]
+}`
+	jsonFile := filepath.Join(t.TempDir(), "all.json")
+	if err := os.WriteFile(jsonFile, []byte(listJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := LoadSnapshotFromPath(jsonFile)
+	if err != nil {
+		t.Fatalf("LoadSnapshotFromPath(json) error = %v", err)
+	}
+	check("json list", fromJSON)
+}
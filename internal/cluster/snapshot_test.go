@@ -0,0 +1,141 @@
+package cluster
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	bv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+)
+
+func claimVolume(claim string) []corev1.Volume {
+	return []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+	}}}
+}
+
+func localPV(name string, nodes ...string) corev1.PersistentVolume {
+	pv := testPV(name, "Bound", "1Gi")
+	pv.Spec.StorageClassName = "local"
+	pv.Spec.Local = &corev1.LocalVolumeSource{Path: "/data"}
+	pv.Spec.NodeAffinity = &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+		MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "kubernetes.io/hostname", Values: nodes}},
+	}}}}
+	return pv
+}
+
+func TestBuildIndexes(t *testing.T) {
+	snap := &Snapshot{
+		Nodes: []corev1.Node{{ObjectMeta: metaV1.ObjectMeta{Name: "n1"}}},
+		Namespaces: []corev1.Namespace{
+			{ObjectMeta: metaV1.ObjectMeta{Name: "a", Annotations: map[string]string{StorageAnnotation: "local,nfs"}}},
+			{ObjectMeta: metaV1.ObjectMeta{Name: "b", Annotations: map[string]string{StorageAnnotation: "local"}}},
+		},
+		PersistentVolumeClaims: []corev1.PersistentVolumeClaim{{ObjectMeta: metaV1.ObjectMeta{Name: "data", Namespace: "a", UID: "uid-1"}}},
+		PersistentVolumes:      []corev1.PersistentVolume{localPV("pv-1", "n1"), localPV("pv-2", "n1", "n2"), testPV("pv-3", "Bound", "1Gi")},
+		Pods: []corev1.Pod{
+			{ObjectMeta: metaV1.ObjectMeta{Name: "p1", Namespace: "a"}, Spec: corev1.PodSpec{Volumes: claimVolume("data")}},
+			{ObjectMeta: metaV1.ObjectMeta{Name: "p2", Namespace: "a"}, Spec: corev1.PodSpec{Volumes: claimVolume("data")}},
+			// 同名 PVC 在其他命名空间，不应关联
+			{ObjectMeta: metaV1.ObjectMeta{Name: "p3", Namespace: "b"}, Spec: corev1.PodSpec{Volumes: claimVolume("data")}},
+		},
+	}
+	snap.buildIndexes()
+
+	if !snap.NodeExists("n1") || snap.NodeExists("n2") {
+		t.Error("NodeExists() does not match the node list")
+	}
+	if pvc, ok := snap.PVC("a", "data"); !ok || pvc.UID != "uid-1" {
+		t.Errorf("PVC(a, data) = %v, %v", pvc, ok)
+	}
+	if _, ok := snap.PVC("b", "data"); ok {
+		t.Error("PVC(b, data) should not exist")
+	}
+	names := func(pods []*corev1.Pod) []string {
+		var out []string
+		for _, p := range pods {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+	if got := names(snap.PodsUsingPVC("a", "data")); !reflect.DeepEqual(got, []string{"p1", "p2"}) {
+		t.Errorf("PodsUsingPVC(a, data) = %v", got)
+	}
+	pvNames := func(pvs []*corev1.PersistentVolume) []string {
+		var out []string
+		for _, pv := range pvs {
+			out = append(out, pv.Name)
+		}
+		return out
+	}
+	if got := pvNames(snap.LocalPVsOnNode("n1")); !reflect.DeepEqual(got, []string{"pv-1", "pv-2"}) {
+		t.Errorf("LocalPVsOnNode(n1) = %v", got)
+	}
+	if got := pvNames(snap.LocalPVsOnNode("n2")); !reflect.DeepEqual(got, []string{"pv-2"}) {
+		t.Errorf("LocalPVsOnNode(n2) = %v", got)
+	}
+	if got := pvNames(snap.PVsByStorageClass("local")); len(got) != 2 {
+		t.Errorf("PVsByStorageClass(local) = %v", got)
+	}
+	if got := snap.NamespacesBoundTo("local"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("NamespacesBoundTo(local) = %v", got)
+	}
+	if got := snap.NamespacesBoundTo("nfs"); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("NamespacesBoundTo(nfs) = %v", got)
+	}
+}
+
+func TestIsPVCUsed(t *testing.T) {
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Volumes: claimVolume("data")}}
+	completed := metaV1.Now()
+	cases := []struct {
+		name string
+		snap Snapshot
+		want bool
+	}{
+		{"unused", Snapshot{}, false},
+		{"pod", Snapshot{Pods: []corev1.Pod{{ObjectMeta: metaV1.ObjectMeta{Namespace: "ns"}, Spec: template.Spec}}}, true},
+		{"pod in other namespace", Snapshot{Pods: []corev1.Pod{{ObjectMeta: metaV1.ObjectMeta{Namespace: "other"}, Spec: template.Spec}}}, false},
+		{"deployment", Snapshot{Deployments: []appsv1.Deployment{{ObjectMeta: metaV1.ObjectMeta{Namespace: "ns"}, Spec: appsv1.DeploymentSpec{Template: template}}}}, true},
+		{"deployment in other namespace", Snapshot{Deployments: []appsv1.Deployment{{ObjectMeta: metaV1.ObjectMeta{Namespace: "other"}, Spec: appsv1.DeploymentSpec{Template: template}}}}, false},
+		{"daemonset", Snapshot{DaemonSets: []appsv1.DaemonSet{{ObjectMeta: metaV1.ObjectMeta{Namespace: "ns"}, Spec: appsv1.DaemonSetSpec{Template: template}}}}, true},
+		{"running job", Snapshot{Jobs: []bv1.Job{{ObjectMeta: metaV1.ObjectMeta{Namespace: "ns"}, Spec: bv1.JobSpec{Template: template}}}}, true},
+		{"completed job", Snapshot{Jobs: []bv1.Job{{ObjectMeta: metaV1.ObjectMeta{Namespace: "ns"}, Spec: bv1.JobSpec{Template: template},
+			Status: bv1.JobStatus{CompletionTime: &completed}}}}, false},
+		{"job in other namespace", Snapshot{Jobs: []bv1.Job{{ObjectMeta: metaV1.ObjectMeta{Namespace: "other"}, Spec: bv1.JobSpec{Template: template}}}}, false},
+		{"cronjob", Snapshot{CronJobs: []bv1.CronJob{{ObjectMeta: metaV1.ObjectMeta{Namespace: "ns"},
+			Spec: bv1.CronJobSpec{JobTemplate: bv1.JobTemplateSpec{Spec: bv1.JobSpec{Template: template}}}}}}, true},
+	}
+	for _, tc := range cases {
+		tc.snap.buildIndexes()
+		if got := tc.snap.isPVCUsed("ns", "data"); got != tc.want {
+			t.Errorf("%s: isPVCUsed() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	// StatefulSet 的 PVC 名称为 <template>-<sts>-<ordinal>，只匹配同一命名空间中的 StatefulSet
+	sts := func(namespace string) Snapshot {
+		return Snapshot{StatefulSets: []appsv1.StatefulSet{{
+			ObjectMeta: metaV1.ObjectMeta{Name: "mysql", Namespace: namespace},
+			Spec:       appsv1.StatefulSetSpec{VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metaV1.ObjectMeta{Name: "data"}}}},
+		}}}
+	}
+	for _, tc := range []struct {
+		name      string
+		snap      Snapshot
+		namespace string
+		pvc       string
+		want      bool
+	}{
+		{"statefulset claim", sts("ns"), "ns", "data-mysql-0", true},
+		{"statefulset in other namespace", sts("other"), "ns", "data-mysql-0", false},
+		{"other statefulset", sts("ns"), "ns", "data-redis-0", false},
+		{"no ordinal", sts("ns"), "ns", "data", false},
+	} {
+		tc.snap.buildIndexes()
+		if got := tc.snap.isPVCUsed(tc.namespace, tc.pvc); got != tc.want {
+			t.Errorf("%s: isPVCUsed(%s) = %v, want %v", tc.name, tc.pvc, got, tc.want)
+		}
+	}
+}
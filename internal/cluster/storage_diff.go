@@ -0,0 +1,217 @@
+package cluster
+
+import (
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
+	"encoding/json"
+	"fmt"
+	"github.com/tealeg/xlsx/v3"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// DiffChange 资源在两个快照之间的变化类型
+type DiffChange string
+
+const (
+	DiffAdded   DiffChange = "Added"
+	DiffRemoved DiffChange = "Removed"
+	DiffChanged DiffChange = "Changed"
+)
+
+// StorageDiffEntry 一条存储资源差异记录
+type StorageDiffEntry struct {
+	Kind   string     `json:"kind"`
+	Name   string     `json:"name"`
+	Change DiffChange `json:"change"`
+	Field  string     `json:"field,omitempty"`
+	Old    string     `json:"old,omitempty"`
+	New    string     `json:"new,omitempty"`
+}
+
+// DiffSnapshots 比较两个快照中的 PV、StorageClass 以及命名空间绑定关系
+func DiffSnapshots(a, b *Snapshot) []StorageDiffEntry {
+	var entries []StorageDiffEntry
+
+	oldPVs := make(map[string]map[string]string)
+	for i := range a.PersistentVolumes {
+		oldPVs[a.PersistentVolumes[i].Name] = pvDiffFields(&a.PersistentVolumes[i])
+	}
+	newPVs := make(map[string]map[string]string)
+	for i := range b.PersistentVolumes {
+		newPVs[b.PersistentVolumes[i].Name] = pvDiffFields(&b.PersistentVolumes[i])
+	}
+	entries = append(entries, diffFields("PV", oldPVs, newPVs)...)
+
+	oldSCs := make(map[string]map[string]string)
+	for i := range a.StorageClasses {
+		oldSCs[a.StorageClasses[i].Name] = scDiffFields(&a.StorageClasses[i])
+	}
+	newSCs := make(map[string]map[string]string)
+	for i := range b.StorageClasses {
+		newSCs[b.StorageClasses[i].Name] = scDiffFields(&b.StorageClasses[i])
+	}
+	entries = append(entries, diffFields("StorageClass", oldSCs, newSCs)...)
+
+	// 命名空间绑定按 StorageClass 比较，记录新增和解除的命名空间
+	scNames := make(map[string]bool)
+	for sc := range a.nsBySC {
+		scNames[sc] = true
+	}
+	for sc := range b.nsBySC {
+		scNames[sc] = true
+	}
+	for _, sc := range sortedKeys(scNames) {
+		before := toSet(a.NamespacesBoundTo(sc))
+		after := toSet(b.NamespacesBoundTo(sc))
+		for _, ns := range sortedKeys(after) {
+			if !before[ns] {
+				entries = append(entries, StorageDiffEntry{Kind: "NamespaceBinding", Name: sc, Change: DiffAdded, New: ns})
+			}
+		}
+		for _, ns := range sortedKeys(before) {
+			if !after[ns] {
+				entries = append(entries, StorageDiffEntry{Kind: "NamespaceBinding", Name: sc, Change: DiffRemoved, Old: ns})
+			}
+		}
+	}
+	return entries
+}
+
+func pvDiffFields(pv *corev1.PersistentVolume) map[string]string {
+	claim := ""
+	if ref := pv.Spec.ClaimRef; ref != nil {
+		claim = ref.Namespace + "/" + ref.Name
+	}
+	return map[string]string{
+		"phase":         string(pv.Status.Phase),
+		"claim":         claim,
+		"capacity":      pv.Spec.Capacity.Storage().String(),
+		"reclaimPolicy": string(pv.Spec.PersistentVolumeReclaimPolicy),
+	}
+}
+
+func scDiffFields(sc *storagev1.StorageClass) map[string]string {
+	reclaimPolicy := "Delete"
+	if sc.ReclaimPolicy != nil {
+		reclaimPolicy = string(*sc.ReclaimPolicy)
+	}
+	return map[string]string{"reclaimPolicy": reclaimPolicy}
+}
+
+// diffFields 按名称比较两组资源，逐字段输出变化
+func diffFields(kind string, before, after map[string]map[string]string) []StorageDiffEntry {
+	names := make(map[string]bool)
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+	var entries []StorageDiffEntry
+	for _, name := range sortedKeys(names) {
+		oldFields, inOld := before[name]
+		newFields, inNew := after[name]
+		switch {
+		case !inOld:
+			entries = append(entries, StorageDiffEntry{Kind: kind, Name: name, Change: DiffAdded})
+		case !inNew:
+			entries = append(entries, StorageDiffEntry{Kind: kind, Name: name, Change: DiffRemoved})
+		default:
+			for _, field := range sortedKeys(oldFields) {
+				if oldFields[field] != newFields[field] {
+					entries = append(entries, StorageDiffEntry{
+						Kind: kind, Name: name, Change: DiffChanged,
+						Field: field, Old: oldFields[field], New: newFields[field],
+					})
+				}
+			}
+		}
+	}
+	return entries
+}
+
+// PrintStorageDiff 以 table 或 json 格式输出差异，filePath 非空时写入 Excel
+func PrintStorageDiff(entries []StorageDiffEntry, format, filePath string) error {
+	if filePath != "" {
+		return writeStorageDiffExcel(entries, filePath)
+	}
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if entries == nil {
+			entries = []StorageDiffEntry{}
+		}
+		return enc.Encode(entries)
+	case "", "table":
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, i18n.Header("KIND", "NAME", "CHANGE", "FIELD", "OLD", "NEW"))
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Kind, e.Name, e.Change, e.Field, e.Old, e.New)
+		}
+		return w.Flush()
+	default:
+		return apperr.ValidationError(i18n.Errorf("diff.unsupported", format))
+	}
+}
+
+func writeStorageDiffExcel(entries []StorageDiffEntry, filePath string) error {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet("StorageDiff")
+	if err != nil {
+		return err
+	}
+	row := sheet.AddRow()
+	row.WriteSlice([]interface{}{"KIND", "NAME", "CHANGE", "FIELD", "OLD", "NEW"}, -1)
+
+	// 新增为绿色，删除为红色，变更为黄色
+	styles := map[DiffChange]*xlsx.Style{
+		DiffAdded:   fillStyle("FFC6EFCE"),
+		DiffRemoved: fillStyle("FFFFC7CE"),
+		DiffChanged: fillStyle("FFFFEB9C"),
+	}
+	for _, e := range entries {
+		row := sheet.AddRow()
+		row.WriteSlice([]interface{}{e.Kind, e.Name, string(e.Change), e.Field, e.Old, e.New}, -1)
+		style := styles[e.Change]
+		_ = row.ForEachCell(func(c *xlsx.Cell) error {
+			c.SetStyle(style)
+			return nil
+		})
+	}
+	if err := file.Save(filePath); err != nil {
+		return err
+	}
+	fmt.Print(i18n.T("diff.file_written", filePath))
+	return nil
+}
+
+// fillStyle 生成纯色背景的单元格样式
+func fillStyle(color string) *xlsx.Style {
+	style := xlsx.NewStyle()
+	style.Fill = *xlsx.NewFill("solid", color, color)
+	style.ApplyFill = true
+	return style
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
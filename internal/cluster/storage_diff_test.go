@@ -0,0 +1,52 @@
+package cluster
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+)
+
+func testPV(name string, phase corev1.PersistentVolumePhase, capacity string) corev1.PersistentVolume {
+	return corev1.PersistentVolume{
+		ObjectMeta: metaV1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:                      corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: phase},
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	a := &Snapshot{
+		PersistentVolumes: []corev1.PersistentVolume{testPV("pv-1", "Bound", "1Gi"), testPV("pv-2", "Released", "1Gi")},
+		StorageClasses:    []storagev1.StorageClass{{ObjectMeta: metaV1.ObjectMeta{Name: "local"}}},
+		Namespaces: []corev1.Namespace{
+			{ObjectMeta: metaV1.ObjectMeta{Name: "ns-a", Annotations: map[string]string{StorageAnnotation: "local"}}},
+		},
+	}
+	b := &Snapshot{
+		PersistentVolumes: []corev1.PersistentVolume{testPV("pv-1", "Released", "2Gi"), testPV("pv-3", "Available", "1Gi")},
+		StorageClasses:    []storagev1.StorageClass{{ObjectMeta: metaV1.ObjectMeta{Name: "local"}}},
+		Namespaces: []corev1.Namespace{
+			{ObjectMeta: metaV1.ObjectMeta{Name: "ns-b", Annotations: map[string]string{StorageAnnotation: "local"}}},
+		},
+	}
+	a.buildIndexes()
+	b.buildIndexes()
+
+	want := []StorageDiffEntry{
+		{Kind: "PV", Name: "pv-1", Change: DiffChanged, Field: "capacity", Old: "1Gi", New: "2Gi"},
+		{Kind: "PV", Name: "pv-1", Change: DiffChanged, Field: "phase", Old: "Bound", New: "Released"},
+		{Kind: "PV", Name: "pv-2", Change: DiffRemoved},
+		{Kind: "PV", Name: "pv-3", Change: DiffAdded},
+		{Kind: "NamespaceBinding", Name: "local", Change: DiffAdded, New: "ns-b"},
+		{Kind: "NamespaceBinding", Name: "local", Change: DiffRemoved, Old: "ns-a"},
+	}
+	if got := DiffSnapshots(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffSnapshots() =\n%+v\nwant\n%+v", got, want)
+	}
+}
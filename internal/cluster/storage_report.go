@@ -0,0 +1,190 @@
+package cluster
+
+import (
+	"devops_tools/internal/i18n"
+	"fmt"
+	"github.com/tealeg/xlsx/v3"
+	corev1 "k8s.io/api/core/v1"
+	"sort"
+)
+
+// formula 写入单元格时按公式处理的字符串
+type formula string
+
+// bytesToGiB 将字节数转换为 GiB，Excel 中以数值保存便于公式计算
+func bytesToGiB(bytes int64) float64 {
+	return float64(bytes) / (1 << 30)
+}
+
+// WriteStorageReport 生成包含汇总、StorageClass、PV、PVC 和孤儿候选的多 sheet 工作簿
+func WriteStorageReport(snap *Snapshot, filePath string) error {
+	pvInfos := snap.PersistentVolumeInfos()
+	orphans := snap.OrphanCandidates()
+	orphanByPV := make(map[string]CleanupReason, len(orphans))
+	for _, c := range orphans {
+		orphanByPV[c.Name] = c.Reason
+	}
+
+	file := xlsx.NewFile()
+	header := xlsx.NewStyle()
+	header.Font.Bold = true
+	header.ApplyFont = true
+	highlight := fillStyle("FFFFC7CE")
+
+	// 汇总 sheet 放在第一页，数据通过公式引用其他 sheet
+	summary, err := addReportSheet(file, "Summary", []string{"STORAGECLASS", "PV COUNT", "CAPACITY (GiB)", "BOUND", "ORPHANS"}, []float64{30, 12, 16, 12, 12}, header)
+	if err != nil {
+		return err
+	}
+	scNames := make(map[string]bool)
+	for _, sc := range snap.StorageClasses {
+		scNames[sc.Name] = true
+	}
+	for _, pv := range pvInfos {
+		scNames[pv.StorageClass] = true
+	}
+	names := sortedKeys(scNames)
+	for i, sc := range names {
+		r := i + 2
+		addReportRow(summary, nil, sc,
+			formula(fmt.Sprintf(`COUNTIF(PersistentVolumes!$G:$G,A%d)`, r)),
+			formula(fmt.Sprintf(`SUMIF(PersistentVolumes!$G:$G,A%d,PersistentVolumes!$B:$B)`, r)),
+			formula(fmt.Sprintf(`COUNTIFS(PersistentVolumes!$G:$G,A%d,PersistentVolumes!$E:$E,"Bound")`, r)),
+			formula(fmt.Sprintf(`COUNTIF(OrphanCandidates!$C:$C,A%d)`, r)),
+		)
+	}
+	last := len(names) + 1
+	addReportRow(summary, header, "TOTAL",
+		formula(fmt.Sprintf("SUM(B2:B%d)", last)),
+		formula(fmt.Sprintf("SUM(C2:C%d)", last)),
+		formula(fmt.Sprintf("SUM(D2:D%d)", last)),
+		formula(fmt.Sprintf("SUM(E2:E%d)", last)),
+	)
+
+	scSheet, err := addReportSheet(file, "StorageClasses", []string{"NAME", "PROVISIONER", "RECLAIM POLICY", "NAMESPACE BOUND", "PV COUNT"}, []float64{30, 30, 16, 40, 12}, header)
+	if err != nil {
+		return err
+	}
+	scInfos := snap.StorageClassInfos()
+	for _, sc := range scInfos {
+		var style *xlsx.Style
+		pvCount := len(snap.PVsByStorageClass(sc.Name))
+		if pvCount == 0 {
+			style = highlight
+		}
+		addReportRow(scSheet, style, sc.Name, sc.Provisioner, sc.ReclaimPolicy, sc.NamespacesBound, pvCount)
+	}
+	setAutoFilter(scSheet, 5, len(scInfos))
+
+	pvSheet, err := addReportSheet(file, "PersistentVolumes", []string{
+		"NAME", "CAPACITY (GiB)", "ACCESS MODES", "RECLAIM POLICY", "STATUS", "CLAIM", "STORAGECLASS",
+		"TYPE", "LOCATION", "AGE", "NODE_ISEXIST", "BONDPVCISEXIST", "PVCINUSE",
+	}, []float64{45, 16, 20, 16, 12, 50, 25, 12, 50, 16, 14, 16, 12}, header)
+	if err != nil {
+		return err
+	}
+	for _, pv := range pvInfos {
+		var style *xlsx.Style
+		if _, orphan := orphanByPV[pv.Name]; orphan || pv.NodeIsExist == "no" {
+			style = highlight
+		}
+		addReportRow(pvSheet, style, pv.Name, bytesToGiB(pv.CapacityBytes), fmt.Sprintf("%v", pv.AccessModes), pv.ReclaimPolicy,
+			string(pv.Status), pv.Claim, pv.StorageClass, pv.Type, pv.Location, pv.Age.String(), pv.NodeIsExist, pv.BondPVCIsExist, pv.PVCInUse)
+	}
+	setAutoFilter(pvSheet, 13, len(pvInfos))
+
+	pvcSheet, err := addReportSheet(file, "PersistentVolumeClaims", []string{
+		"NAMESPACE", "NAME", "STATUS", "VOLUME", "STORAGECLASS", "REQUESTED (GiB)", "ACCESS MODES", "INUSE",
+	}, []float64{30, 40, 12, 45, 25, 16, 20, 10}, header)
+	if err != nil {
+		return err
+	}
+	pvcs := append([]corev1.PersistentVolumeClaim{}, snap.PersistentVolumeClaims...)
+	sort.Slice(pvcs, func(i, j int) bool {
+		if pvcs[i].Namespace != pvcs[j].Namespace {
+			return pvcs[i].Namespace < pvcs[j].Namespace
+		}
+		return pvcs[i].Name < pvcs[j].Name
+	})
+	for _, pvc := range pvcs {
+		sc := ""
+		if pvc.Spec.StorageClassName != nil {
+			sc = *pvc.Spec.StorageClassName
+		}
+		inUse := snap.isPVCUsed(pvc.Namespace, pvc.Name)
+		var style *xlsx.Style
+		if !inUse {
+			style = highlight
+		}
+		addReportRow(pvcSheet, style, pvc.Namespace, pvc.Name, string(pvc.Status.Phase), pvc.Spec.VolumeName, sc,
+			bytesToGiB(pvc.Spec.Resources.Requests.Storage().Value()), fmt.Sprintf("%v", pvc.Spec.AccessModes), inUse)
+	}
+	setAutoFilter(pvcSheet, 8, len(pvcs))
+
+	orphanSheet, err := addReportSheet(file, "OrphanCandidates", []string{"NAME", "REASON", "STORAGECLASS", "CAPACITY (GiB)", "CLAIM", "LOCATION"}, []float64{45, 20, 25, 16, 50, 50}, header)
+	if err != nil {
+		return err
+	}
+	pvByName := make(map[string]PVInfo, len(pvInfos))
+	for _, pv := range pvInfos {
+		pvByName[pv.Name] = pv
+	}
+	for _, c := range orphans {
+		pv := pvByName[c.Name]
+		addReportRow(orphanSheet, highlight, c.Name, string(c.Reason), pv.StorageClass, bytesToGiB(pv.CapacityBytes), pv.Claim, pv.Location)
+	}
+	setAutoFilter(orphanSheet, 6, len(orphans))
+
+	if err := file.Save(filePath); err != nil {
+		return err
+	}
+	fmt.Print(i18n.T("report.file_written", filePath))
+	return nil
+}
+
+// addReportSheet 创建 sheet，写入加粗表头并冻结首行
+func addReportSheet(file *xlsx.File, name string, headers []string, widths []float64, headerStyle *xlsx.Style) (*xlsx.Sheet, error) {
+	sheet, err := file.AddSheet(name)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(headers))
+	for i, h := range headers {
+		values[i] = h
+	}
+	addReportRow(sheet, headerStyle, values...)
+	for i, width := range widths {
+		sheet.SetColWidth(i+1, i+1, width)
+	}
+	sheet.SheetViews = []xlsx.SheetView{{
+		Pane: &xlsx.Pane{YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft", State: "frozen"},
+	}}
+	return sheet, nil
+}
+
+// addReportRow 追加一行，float64 写为两位小数的数值，formula 写为公式
+func addReportRow(sheet *xlsx.Sheet, style *xlsx.Style, values ...interface{}) {
+	row := sheet.AddRow()
+	for _, v := range values {
+		cell := row.AddCell()
+		switch val := v.(type) {
+		case float64:
+			cell.SetFloatWithFormat(val, "0.00")
+		case formula:
+			cell.SetFormula(string(val))
+		default:
+			cell.SetValue(val)
+		}
+		if style != nil {
+			cell.SetStyle(style)
+		}
+	}
+}
+
+// setAutoFilter 为表头和数据区域开启自动筛选
+func setAutoFilter(sheet *xlsx.Sheet, cols, rows int) {
+	sheet.AutoFilter = &xlsx.AutoFilter{
+		TopLeftCell:     "A1",
+		BottomRightCell: xlsx.GetCellIDStringFromCoords(cols-1, rows),
+	}
+}
@@ -0,0 +1,89 @@
+package cluster
+
+import (
+	"github.com/tealeg/xlsx/v3"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteStorageReport(t *testing.T) {
+	pv := testPV("pv-1", "Available", "1Gi")
+	pv.Spec.StorageClassName = "local"
+	snap := &Snapshot{
+		StorageClasses:    []storagev1.StorageClass{{ObjectMeta: metaV1.ObjectMeta{Name: "local"}}},
+		PersistentVolumes: []corev1.PersistentVolume{pv, testPV("pv-2", "Bound", "2Gi")},
+	}
+	snap.buildIndexes()
+
+	path := filepath.Join(t.TempDir(), "report.xlsx")
+	if err := WriteStorageReport(snap, path); err != nil {
+		t.Fatalf("WriteStorageReport() error = %v", err)
+	}
+	file, err := xlsx.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+
+	var names []string
+	for _, sheet := range file.Sheets {
+		names = append(names, sheet.Name)
+	}
+	wantNames := []string{"Summary", "StorageClasses", "PersistentVolumes", "PersistentVolumeClaims", "OrphanCandidates"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("sheets = %v, want %v", names, wantNames)
+	}
+
+	cell := func(sheet string, row, col int) *xlsx.Cell {
+		t.Helper()
+		c, err := file.Sheet[sheet].Cell(row, col)
+		if err != nil {
+			t.Fatalf("%s!(%d,%d): %v", sheet, row, col, err)
+		}
+		return c
+	}
+	headers := map[string][]string{
+		"Summary":          {"STORAGECLASS", "PV COUNT", "CAPACITY (GiB)", "BOUND", "ORPHANS"},
+		"StorageClasses":   {"NAME", "PROVISIONER", "RECLAIM POLICY", "NAMESPACE BOUND", "PV COUNT"},
+		"OrphanCandidates": {"NAME", "REASON", "STORAGECLASS", "CAPACITY (GiB)", "CLAIM", "LOCATION"},
+	}
+	for sheet, want := range headers {
+		for i, h := range want {
+			if got := cell(sheet, 0, i).Value; got != h {
+				t.Errorf("%s header[%d] = %q, want %q", sheet, i, got, h)
+			}
+		}
+	}
+
+	// StorageClass 名称排序后 pv-2 未设置 StorageClass 的空行在前，local 位于第 3 行
+	if got := cell("Summary", 2, 0).Value; got != "local" {
+		t.Fatalf("Summary A3 = %q, want local", got)
+	}
+	formulas := []string{
+		"COUNTIF(PersistentVolumes!$G:$G,A3)",
+		"SUMIF(PersistentVolumes!$G:$G,A3,PersistentVolumes!$B:$B)",
+		`COUNTIFS(PersistentVolumes!$G:$G,A3,PersistentVolumes!$E:$E,"Bound")`,
+		"COUNTIF(OrphanCandidates!$C:$C,A3)",
+	}
+	for i, want := range formulas {
+		if got := cell("Summary", 2, i+1).Formula(); got != want {
+			t.Errorf("Summary formula[%d] = %q, want %q", i+1, got, want)
+		}
+	}
+	if got := cell("Summary", 3, 1).Formula(); got != "SUM(B2:B3)" {
+		t.Errorf("Summary TOTAL formula = %q, want SUM(B2:B3)", got)
+	}
+
+	if got := cell("OrphanCandidates", 1, 0).Value; got != "pv-1" {
+		t.Errorf("OrphanCandidates name = %q, want pv-1", got)
+	}
+	if got := cell("OrphanCandidates", 1, 1).Value; got != string(ReasonAvailable) {
+		t.Errorf("OrphanCandidates reason = %q, want %s", got, ReasonAvailable)
+	}
+	if got := cell("OrphanCandidates", 1, 2).Value; got != "local" {
+		t.Errorf("OrphanCandidates storageclass = %q, want local", got)
+	}
+}
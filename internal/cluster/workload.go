@@ -0,0 +1,362 @@
+package cluster
+
+import (
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
+	"fmt"
+	"github.com/tealeg/xlsx/v3"
+	corev1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// 工作负载类型
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+	KindDaemonSet   = "DaemonSet"
+	KindCronJob     = "CronJob"
+	KindJob         = "Job"
+)
+
+// workload 各类工作负载的公共视图，Pod 模板、副本数和关联的 Pod
+type workload struct {
+	kind   string
+	meta   metaV1.ObjectMeta
+	spec   *corev1.PodSpec
+	ready  string
+	claims []corev1.PersistentVolumeClaim
+	pods   []*corev1.Pod
+}
+
+// workloads 将快照中的工作负载统一为 workload，并按 selector 或 ownerReferences 关联 Pod。
+// Job 和 CronJob 的 Pod 通过 ownerReferences 关联，CronJob 创建的 Job 不再单独列出
+func (s *Snapshot) workloads() []workload {
+	podsByOwner := make(map[types.UID][]*corev1.Pod)
+	podsByNamespace := make(map[string][]*corev1.Pod)
+	for i := range s.Pods {
+		pod := &s.Pods[i]
+		podsByNamespace[pod.Namespace] = append(podsByNamespace[pod.Namespace], pod)
+		for _, ref := range pod.OwnerReferences {
+			podsByOwner[ref.UID] = append(podsByOwner[ref.UID], pod)
+		}
+	}
+	selectPods := func(namespace string, selector *metaV1.LabelSelector) []*corev1.Pod {
+		sel, err := metaV1.LabelSelectorAsSelector(selector)
+		if err != nil || sel.Empty() {
+			return nil
+		}
+		var pods []*corev1.Pod
+		for _, pod := range podsByNamespace[namespace] {
+			if sel.Matches(labels.Set(pod.Labels)) {
+				pods = append(pods, pod)
+			}
+		}
+		return pods
+	}
+	replicas := func(ready int32, desired *int32) string {
+		n := int32(1)
+		if desired != nil {
+			n = *desired
+		}
+		return fmt.Sprintf("%d/%d", ready, n)
+	}
+
+	var list []workload
+	for i := range s.Deployments {
+		d := &s.Deployments[i]
+		list = append(list, workload{kind: KindDeployment, meta: d.ObjectMeta, spec: &d.Spec.Template.Spec,
+			ready: replicas(d.Status.ReadyReplicas, d.Spec.Replicas), pods: selectPods(d.Namespace, d.Spec.Selector)})
+	}
+	for i := range s.StatefulSets {
+		sts := &s.StatefulSets[i]
+		list = append(list, workload{kind: KindStatefulSet, meta: sts.ObjectMeta, spec: &sts.Spec.Template.Spec,
+			ready: replicas(sts.Status.ReadyReplicas, sts.Spec.Replicas), claims: sts.Spec.VolumeClaimTemplates,
+			pods: selectPods(sts.Namespace, sts.Spec.Selector)})
+	}
+	for i := range s.DaemonSets {
+		ds := &s.DaemonSets[i]
+		list = append(list, workload{kind: KindDaemonSet, meta: ds.ObjectMeta, spec: &ds.Spec.Template.Spec,
+			ready: fmt.Sprintf("%d/%d", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled), pods: selectPods(ds.Namespace, ds.Spec.Selector)})
+	}
+	jobsByCronJob := make(map[types.UID][]types.UID)
+	for i := range s.Jobs {
+		job := &s.Jobs[i]
+		if owner := metaV1.GetControllerOf(job); owner != nil && owner.Kind == KindCronJob {
+			jobsByCronJob[owner.UID] = append(jobsByCronJob[owner.UID], job.UID)
+			continue
+		}
+		// Job 的 READY 为成功完成数/期望完成数
+		list = append(list, workload{kind: KindJob, meta: job.ObjectMeta, spec: &job.Spec.Template.Spec,
+			ready: replicas(job.Status.Succeeded, job.Spec.Completions), pods: podsByOwner[job.UID]})
+	}
+	for i := range s.CronJobs {
+		cj := &s.CronJobs[i]
+		w := workload{kind: KindCronJob, meta: cj.ObjectMeta, spec: &cj.Spec.JobTemplate.Spec.Template.Spec, ready: "-"}
+		for _, uid := range jobsByCronJob[cj.UID] {
+			w.pods = append(w.pods, podsByOwner[uid]...)
+		}
+		list = append(list, w)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].meta.Namespace != list[j].meta.Namespace {
+			return list[i].meta.Namespace < list[j].meta.Namespace
+		}
+		if list[i].kind != list[j].kind {
+			return list[i].kind < list[j].kind
+		}
+		return list[i].meta.Name < list[j].meta.Name
+	})
+	return list
+}
+
+// ContainerInfo 工作负载模板中的一个容器
+type ContainerInfo struct {
+	Name     string
+	Init     bool
+	Image    string
+	Repo     string
+	Tag      string
+	Digest   string
+	Requests corev1.ResourceList
+	Limits   corev1.ResourceList
+}
+
+// VolumeClaimInfo 工作负载挂载的 PVC，StatefulSet 的 volumeClaimTemplates 以模板名称出现
+type VolumeClaimInfo struct {
+	Name         string
+	StorageClass string
+	Template     bool
+}
+
+// WorkloadInfo get-workload 中一个工作负载的展示信息。Requests 和 Limits 为单个 Pod 的合计
+type WorkloadInfo struct {
+	Kind       string
+	Namespace  string
+	Name       string
+	Ready      string
+	Containers []ContainerInfo
+	Requests   corev1.ResourceList
+	Limits     corev1.ResourceList
+	Volumes    []VolumeClaimInfo
+	// Nodes 关联 Pod 所在节点及 Pod 数量
+	Nodes map[string]int
+	Age   time.Duration
+}
+
+// WorkloadFilter get-workload 的过滤条件，零值不过滤
+type WorkloadFilter struct {
+	Namespace string
+	Kinds     []string
+}
+
+// Validate 检查 Kinds 中的类型，大小写不敏感
+func (f WorkloadFilter) Validate() error {
+	for _, k := range f.Kinds {
+		switch {
+		case strings.EqualFold(k, KindDeployment), strings.EqualFold(k, KindStatefulSet), strings.EqualFold(k, KindDaemonSet),
+			strings.EqualFold(k, KindCronJob), strings.EqualFold(k, KindJob):
+		default:
+			return apperr.ValidationError(i18n.Errorf("workload.unknown_kind", k))
+		}
+	}
+	return nil
+}
+
+func (f WorkloadFilter) match(kind, namespace string) bool {
+	if f.Namespace != "" && namespace != f.Namespace {
+		return false
+	}
+	if len(f.Kinds) == 0 {
+		return true
+	}
+	for _, k := range f.Kinds {
+		if strings.EqualFold(k, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkloadInfos 汇总满足 filter 的工作负载的副本、镜像、资源、PVC 和节点分布
+func (s *Snapshot) WorkloadInfos(filter WorkloadFilter) []WorkloadInfo {
+	var infos []WorkloadInfo
+	for _, w := range s.workloads() {
+		if !filter.match(w.kind, w.meta.Namespace) {
+			continue
+		}
+		info := WorkloadInfo{
+			Kind:      w.kind,
+			Namespace: w.meta.Namespace,
+			Name:      w.meta.Name,
+			Ready:     w.ready,
+			Requests:  podResources(w.spec, false),
+			Limits:    podResources(w.spec, true),
+			Nodes:     make(map[string]int),
+			Age:       time.Since(w.meta.CreationTimestamp.Time).Round(time.Second),
+		}
+		for _, c := range w.spec.InitContainers {
+			info.Containers = append(info.Containers, containerInfo(c, true))
+		}
+		for _, c := range w.spec.Containers {
+			info.Containers = append(info.Containers, containerInfo(c, false))
+		}
+		for _, v := range w.spec.Volumes {
+			if v.PersistentVolumeClaim == nil {
+				continue
+			}
+			claim := VolumeClaimInfo{Name: v.PersistentVolumeClaim.ClaimName}
+			if pvc, ok := s.PVC(w.meta.Namespace, claim.Name); ok && pvc.Spec.StorageClassName != nil {
+				claim.StorageClass = *pvc.Spec.StorageClassName
+			}
+			info.Volumes = append(info.Volumes, claim)
+		}
+		for _, t := range w.claims {
+			claim := VolumeClaimInfo{Name: t.Name, Template: true}
+			if t.Spec.StorageClassName != nil {
+				claim.StorageClass = *t.Spec.StorageClassName
+			}
+			info.Volumes = append(info.Volumes, claim)
+		}
+		for _, pod := range w.pods {
+			if pod.Spec.NodeName != "" {
+				info.Nodes[pod.Spec.NodeName]++
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func containerInfo(c corev1.Container, init bool) ContainerInfo {
+	repo, tag, digest := splitImage(c.Image)
+	return ContainerInfo{Name: c.Name, Init: init, Image: c.Image, Repo: repo, Tag: tag, Digest: digest,
+		Requests: c.Resources.Requests, Limits: c.Resources.Limits}
+}
+
+// splitImage 将镜像引用拆分为仓库、tag 和 digest，未指定 tag 和 digest 时 tag 为 latest
+func splitImage(image string) (repo, tag, digest string) {
+	repo = image
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo, digest = repo[:i], repo[i+1:]
+	}
+	// 冒号在最后一个 / 之后才是 tag，否则是仓库地址中的端口
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo, tag = repo[:i], repo[i+1:]
+	}
+	if tag == "" && digest == "" {
+		tag = "latest"
+	}
+	return repo, tag, digest
+}
+
+// formatResources 以 cpu=500m,memory=1Gi 的形式显示 CPU 和内存，都未设置时返回 -
+func formatResources(list corev1.ResourceList) string {
+	var parts []string
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if q, ok := list[name]; ok {
+			parts = append(parts, string(name)+"="+q.String())
+		}
+	}
+	return joinOrDash(parts)
+}
+
+func (w WorkloadInfo) images() []string {
+	images := make([]string, 0, len(w.Containers))
+	for _, c := range w.Containers {
+		if !c.Init {
+			images = append(images, c.Image)
+		}
+	}
+	return images
+}
+
+func (w WorkloadInfo) volumes() []string {
+	volumes := make([]string, 0, len(w.Volumes))
+	for _, v := range w.Volumes {
+		volumes = append(volumes, v.Name+"("+v.StorageClass+")")
+	}
+	return volumes
+}
+
+// nodeSpread 以 node-1(2),node-2(1) 的形式显示 Pod 在节点上的分布
+func (w WorkloadInfo) nodeSpread() []string {
+	spread := make([]string, 0, len(w.Nodes))
+	for _, node := range sortedKeys(w.Nodes) {
+		spread = append(spread, node+"("+strconv.Itoa(w.Nodes[node])+")")
+	}
+	return spread
+}
+
+// GetWorkloadInfo 输出工作负载清单。导出 Excel 时分别写入工作负载、容器、PVC 和节点分布四个工作表
+func GetWorkloadInfo(snap *Snapshot, filePath string, filter WorkloadFilter) error {
+	infos := snap.WorkloadInfos(filter)
+	if filePath == "" {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, i18n.Header("NAMESPACE", "KIND", "NAME", "READY", "IMAGES", "REQUESTS", "LIMITS", "PVCS", "NODES", "AGE"))
+		for _, wl := range infos {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				wl.Namespace, wl.Kind, wl.Name, wl.Ready, joinOrDash(wl.images()), formatResources(wl.Requests), formatResources(wl.Limits),
+				joinOrDash(wl.volumes()), joinOrDash(wl.nodeSpread()), wl.Age)
+		}
+		return w.Flush()
+	}
+
+	file := xlsx.NewFile()
+	sheets := make(map[string]*xlsx.Sheet)
+	for _, s := range []struct {
+		name   string
+		header []string
+	}{
+		{"Workloads", []string{"NAMESPACE", "KIND", "NAME", "READY", "REQUESTS", "LIMITS", "PVCS", "NODES", "AGE"}},
+		{"Containers", []string{"NAMESPACE", "KIND", "WORKLOAD", "CONTAINER", "INIT", "IMAGE", "REPOSITORY", "TAG", "DIGEST",
+			"CPU REQUEST", "MEMORY REQUEST", "CPU LIMIT", "MEMORY LIMIT"}},
+		{"Volumes", []string{"NAMESPACE", "KIND", "WORKLOAD", "PVC", "STORAGECLASS", "TEMPLATE"}},
+		{"NodeSpread", []string{"NAMESPACE", "KIND", "WORKLOAD", "NODE", "PODS"}},
+	} {
+		sheet, err := file.AddSheet(s.name)
+		if err != nil {
+			return err
+		}
+		sheet.AddRow().WriteSlice(s.header, -1)
+		sheets[s.name] = sheet
+	}
+	quantity := func(list corev1.ResourceList, name corev1.ResourceName) string {
+		if q, ok := list[name]; ok {
+			return q.String()
+		}
+		return ""
+	}
+	for _, wl := range infos {
+		sheets["Workloads"].AddRow().WriteSlice([]interface{}{
+			wl.Namespace, wl.Kind, wl.Name, wl.Ready, formatResources(wl.Requests), formatResources(wl.Limits),
+			strings.Join(wl.volumes(), ","), strings.Join(wl.nodeSpread(), ","), wl.Age.String(),
+		}, -1)
+		for _, c := range wl.Containers {
+			sheets["Containers"].AddRow().WriteSlice([]interface{}{
+				wl.Namespace, wl.Kind, wl.Name, c.Name, c.Init, c.Image, c.Repo, c.Tag, c.Digest,
+				quantity(c.Requests, corev1.ResourceCPU), quantity(c.Requests, corev1.ResourceMemory),
+				quantity(c.Limits, corev1.ResourceCPU), quantity(c.Limits, corev1.ResourceMemory),
+			}, -1)
+		}
+		for _, v := range wl.Volumes {
+			sheets["Volumes"].AddRow().WriteSlice([]interface{}{wl.Namespace, wl.Kind, wl.Name, v.Name, v.StorageClass, v.Template}, -1)
+		}
+		for _, node := range sortedKeys(wl.Nodes) {
+			sheets["NodeSpread"].AddRow().WriteSlice([]interface{}{wl.Namespace, wl.Kind, wl.Name, node, wl.Nodes[node]}, -1)
+		}
+	}
+	if err := file.Save(filePath); err != nil {
+		return err
+	}
+	fmt.Print(i18n.T("workload.file_written", filePath))
+	return nil
+}
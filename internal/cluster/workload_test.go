@@ -0,0 +1,86 @@
+package cluster
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	bv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestSplitImage(t *testing.T) {
+	for _, tc := range []struct{ image, repo, tag, digest string }{
+		{"nginx", "nginx", "latest", ""},
+		{"registry:5000/app/web:1.2", "registry:5000/app/web", "1.2", ""},
+		{"registry:5000/app/web", "registry:5000/app/web", "latest", ""},
+		{"busybox:1.36@sha256:abc", "busybox", "1.36", "sha256:abc"},
+		{"busybox@sha256:abc", "busybox", "", "sha256:abc"},
+	} {
+		repo, tag, digest := splitImage(tc.image)
+		if repo != tc.repo || tag != tc.tag || digest != tc.digest {
+			t.Errorf("splitImage(%s) = %s, %s, %s", tc.image, repo, tag, digest)
+		}
+	}
+}
+
+func TestWorkloadInfos(t *testing.T) {
+	isController := true
+	sc := "local"
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "app", Image: "app:1.0", Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+		}}},
+		Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+		}}},
+	}}
+	pod := func(name, node string, labels map[string]string, owner metaV1.Object) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: metaV1.ObjectMeta{Name: name, Namespace: "ns", Labels: labels}, Spec: corev1.PodSpec{NodeName: node}}
+		if owner != nil {
+			p.OwnerReferences = []metaV1.OwnerReference{{UID: owner.GetUID()}}
+		}
+		return p
+	}
+	cj := bv1.CronJob{ObjectMeta: metaV1.ObjectMeta{Name: "report", Namespace: "ns", UID: "cj"}}
+	job := bv1.Job{ObjectMeta: metaV1.ObjectMeta{Name: "report-1", Namespace: "ns", UID: "job",
+		OwnerReferences: []metaV1.OwnerReference{{Kind: KindCronJob, UID: "cj", Controller: &isController}}}}
+	snap := &Snapshot{
+		Deployments: []appsv1.Deployment{{
+			ObjectMeta: metaV1.ObjectMeta{Name: "web", Namespace: "ns"},
+			Spec:       appsv1.DeploymentSpec{Selector: &metaV1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}, Template: template},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+		}},
+		CronJobs: []bv1.CronJob{cj},
+		Jobs:     []bv1.Job{job},
+		Pods: []corev1.Pod{
+			pod("web-1", "n1", map[string]string{"app": "web"}, nil),
+			pod("web-2", "n1", map[string]string{"app": "web"}, nil),
+			pod("other", "n2", map[string]string{"app": "other"}, nil),
+			pod("report-1-x", "n2", nil, &job),
+		},
+		PersistentVolumeClaims: []corev1.PersistentVolumeClaim{{
+			ObjectMeta: metaV1.ObjectMeta{Name: "data", Namespace: "ns"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &sc},
+		}},
+	}
+	snap.buildIndexes()
+	infos := snap.WorkloadInfos(WorkloadFilter{})
+	// CronJob 创建的 Job 不单独列出
+	if len(infos) != 2 || infos[0].Kind != KindCronJob || infos[1].Kind != KindDeployment {
+		t.Fatalf("WorkloadInfos() = %+v", infos)
+	}
+	if spread := joinOrDash(infos[0].nodeSpread()); spread != "n2(1)" {
+		t.Errorf("CronJob node spread = %s", spread)
+	}
+	web := infos[1]
+	if web.Ready != "1/1" || joinOrDash(web.nodeSpread()) != "n1(2)" || joinOrDash(web.volumes()) != "data(local)" {
+		t.Errorf("web = %+v", web)
+	}
+	if got := formatResources(web.Requests); got != "cpu=250m" {
+		t.Errorf("requests = %s", got)
+	}
+	if got := snap.WorkloadInfos(WorkloadFilter{Kinds: []string{"deployment"}}); len(got) != 1 {
+		t.Errorf("kind filter returned %d workloads", len(got))
+	}
+}
@@ -0,0 +1,126 @@
+package completion
+
+import (
+	"context"
+	"devops_tools/internal/api"
+	"github.com/spf13/cobra"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"strings"
+	"time"
+)
+
+// 补全需要在按下 tab 后尽快返回
+const timeout = 5 * time.Second
+
+// Func cobra 的 ValidArgsFunction 以及 RegisterFlagCompletionFunc 使用的函数签名
+type Func func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// fromCluster 查询集群获得候选项，连接失败时不给出候选，也不回退到文件名补全
+func fromCluster(list func(ctx context.Context, client kubernetes.Interface) ([]string, error)) Func {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		client, err := api.NewClient()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		names, err := list(ctx, client)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return filter(names, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// filter 保留以 toComplete 开头且未出现在 args 中的候选项
+func filter(names, args []string, toComplete string) []string {
+	used := make(map[string]bool, len(args))
+	for _, arg := range args {
+		used[arg] = true
+	}
+	var out []string
+	for _, name := range names {
+		if !used[name] && strings.HasPrefix(name, toComplete) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// StorageClasses 补全 StorageClass 名称
+var StorageClasses = fromCluster(func(ctx context.Context, client kubernetes.Interface) ([]string, error) {
+	list, err := client.StorageV1().StorageClasses().List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	return names, nil
+})
+
+// PersistentVolumes 补全 PV 名称
+var PersistentVolumes = fromCluster(func(ctx context.Context, client kubernetes.Interface) ([]string, error) {
+	list, err := client.CoreV1().PersistentVolumes().List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	return names, nil
+})
+
+// Namespaces 补全命名空间名称
+var Namespaces = fromCluster(func(ctx context.Context, client kubernetes.Interface) ([]string, error) {
+	list, err := client.CoreV1().Namespaces().List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	return names, nil
+})
+
+// Nodes 补全节点名称
+var Nodes = fromCluster(func(ctx context.Context, client kubernetes.Interface) ([]string, error) {
+	list, err := client.CoreV1().Nodes().List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	return names, nil
+})
+
+// Contexts 补全 kubeconfig 中的 context，不需要访问集群
+func Contexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := api.Contexts()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return filter(names, nil, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// ClusterSigns 补全配置文件中登记的集群标识，描述信息一并显示
+func ClusterSigns(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var out []string
+	for _, sign := range filter(api.ClusterSigns(), nil, toComplete) {
+		out = append(out, cobra.CompletionWithDesc(sign, api.Clusters[sign].Description))
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Fixed 补全固定的取值，例如 --mode 和 --output
+func Fixed(values ...string) Func {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filter(values, nil, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
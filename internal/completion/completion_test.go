@@ -0,0 +1,13 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	got := filter([]string{"local-a", "local-b", "nfs"}, []string{"local-a"}, "local")
+	if want := []string{"local-b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("filter() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"devops_tools/internal/api"
+	"devops_tools/internal/cluster"
+	"github.com/spf13/cobra"
+	"strconv"
+	"strings"
+)
+
+// cleanupCommand cleanup.* 配置只作用于该命令，避免同名的 --dry-run 等 flag 影响其他命令
+const cleanupCommand = "clean-storage"
+
+// flagValues 配置项对应的 flag 名称和取值，未设置的配置项不出现
+func (s *Settings) flagValues(cmd *cobra.Command) map[string]string {
+	values := map[string]string{
+		"kubeconfig":   s.Kubeconfig,
+		"context":      s.Context,
+		"cluster-sign": s.ClusterSign,
+		"output":       s.Output,
+		"backup-dir":   s.BackupDir,
+		"lang":         s.Lang,
+	}
+	if s.QPS != 0 {
+		values["qps"] = strconv.FormatFloat(float64(s.QPS), 'f', -1, 32)
+	}
+	if s.Burst != 0 {
+		values["burst"] = strconv.Itoa(s.Burst)
+	}
+	if len(s.AllowedRegistries) > 0 {
+		values["allowed-registry"] = strings.Join(s.AllowedRegistries, ",")
+	}
+	if cmd.Name() != cleanupCommand {
+		return nonEmpty(values)
+	}
+	if s.Cleanup.Concurrency != 0 {
+		values["concurrency"] = strconv.Itoa(s.Cleanup.Concurrency)
+	}
+	if s.Cleanup.DryRun != nil {
+		values["dry-run"] = strconv.FormatBool(*s.Cleanup.DryRun)
+	}
+	if len(s.Cleanup.Reasons) > 0 {
+		reasons := make([]string, len(s.Cleanup.Reasons))
+		for i, r := range s.Cleanup.Reasons {
+			reasons[i] = string(r)
+		}
+		values["reason"] = strings.Join(reasons, ",")
+	}
+	return nonEmpty(values)
+}
+
+func nonEmpty(values map[string]string) map[string]string {
+	for name, value := range values {
+		if value == "" {
+			delete(values, name)
+		}
+	}
+	return values
+}
+
+// Apply 将配置作为 cmd 上未在命令行指定的 flag 的默认值，优先级为 flag > 环境变量 > profile > 配置文件
+func (c *Config) Apply(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	for name, value := range c.flagValues(cmd) {
+		f := flags.Lookup(name)
+		if f == nil || f.Changed {
+			continue
+		}
+		if err := f.Value.Set(value); err != nil {
+			return err
+		}
+	}
+	cluster.CSIDecoders = c.CSI
+	api.Clusters = c.Clusters
+	return nil
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"devops_tools/internal/api"
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/i18n"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"strconv"
+)
+
+// SystemPath 系统级配置文件，用户级配置 ~/.devops-tool.yaml 覆盖其中的同名字段
+const SystemPath = "/etc/devops-tool/config.yaml"
+
+// UserPath 返回用户级配置文件路径
+func UserPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".devops-tool.yaml")
+}
+
+// Settings 可以写在顶层或 profile 中的配置项，零值表示未设置
+type Settings struct {
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	Context    string `json:"context,omitempty"`
+	// ClusterSign 默认使用的集群标识，需要在 clusters 中登记
+	ClusterSign string  `json:"clusterSign,omitempty"`
+	Output      string  `json:"output,omitempty"`
+	BackupDir   string  `json:"backupDir,omitempty"`
+	Lang        string  `json:"lang,omitempty"`
+	QPS         float32 `json:"qps,omitempty"`
+	Burst       int     `json:"burst,omitempty"`
+	Cleanup     Cleanup `json:"cleanup,omitempty"`
+	// CSI 解析 CSI PV 类型和位置的规则
+	CSI []cluster.CSIDecoder `json:"csi,omitempty"`
+	// AllowedRegistries get-images 允许的镜像仓库
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+}
+
+// Cleanup clean-storage 的默认清理策略
+type Cleanup struct {
+	Concurrency int   `json:"concurrency,omitempty"`
+	DryRun      *bool `json:"dryRun,omitempty"`
+	// Reasons 只清理这些原因的资源，为空时清理全部
+	Reasons []cluster.CleanupReason `json:"reasons,omitempty"`
+}
+
+// Config 配置文件内容。选中的 profile 覆盖顶层配置中出现的字段
+type Config struct {
+	Settings       `json:",inline"`
+	CurrentProfile string                     `json:"currentProfile,omitempty"`
+	Profiles       map[string]json.RawMessage `json:"profiles,omitempty"`
+	// Clusters 集群登记表，多个配置文件中的登记按标识合并
+	Clusters map[string]api.Cluster `json:"clusters,omitempty"`
+
+	// Profile 实际生效的 profile，Sources 按顺序加载的配置文件，均不写入文件
+	Profile string   `json:"-"`
+	Sources []string `json:"-"`
+}
+
+// Load 按 /etc/devops-tool/config.yaml、~/.devops-tool.yaml 的顺序叠加配置，
+// path 非空时只加载该文件（也可以通过 DEVOPS_TOOL_CONFIG 指定）。
+// 之后应用 profile（profile 参数、DEVOPS_TOOL_PROFILE、currentProfile 依次生效），最后应用环境变量
+func Load(path, profile string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv("DEVOPS_TOOL_CONFIG")
+	}
+	paths := []string{SystemPath, UserPath()}
+	if path != "" {
+		paths = []string{path}
+	}
+	cfg := &Config{}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if errors.Is(err, os.ErrNotExist) && path == "" {
+			continue
+		}
+		if err != nil {
+			return nil, i18n.Errorf("config.read_failed", p, err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, i18n.Errorf("config.parse_failed", p, err)
+		}
+		cfg.Sources = append(cfg.Sources, p)
+	}
+
+	if profile == "" {
+		profile = os.Getenv("DEVOPS_TOOL_PROFILE")
+	}
+	if profile == "" {
+		profile = cfg.CurrentProfile
+	}
+	if profile != "" {
+		raw, ok := cfg.Profiles[profile]
+		if !ok {
+			return nil, i18n.Errorf("config.profile_missing", profile)
+		}
+		if err := json.Unmarshal(raw, &cfg.Settings); err != nil {
+			return nil, i18n.Errorf("config.profile_parse_failed", profile, err)
+		}
+		cfg.Profile = profile
+	}
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv 以 DEVOPS_TOOL_ 开头的环境变量覆盖配置
+func (c *Config) applyEnv() error {
+	for env, target := range map[string]*string{
+		"DEVOPS_TOOL_KUBECONFIG":   &c.Kubeconfig,
+		"DEVOPS_TOOL_CONTEXT":      &c.Context,
+		"DEVOPS_TOOL_CLUSTER_SIGN": &c.ClusterSign,
+		"DEVOPS_TOOL_OUTPUT":       &c.Output,
+		"DEVOPS_TOOL_BACKUP_DIR":   &c.BackupDir,
+		"DEVOPS_TOOL_LANG":         &c.Lang,
+	} {
+		if v, ok := os.LookupEnv(env); ok {
+			*target = v
+		}
+	}
+	if v, ok := os.LookupEnv("DEVOPS_TOOL_QPS"); ok {
+		qps, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return i18n.Errorf("config.env_invalid", "DEVOPS_TOOL_QPS", err)
+		}
+		c.QPS = float32(qps)
+	}
+	for env, target := range map[string]*int{
+		"DEVOPS_TOOL_BURST":               &c.Burst,
+		"DEVOPS_TOOL_CLEANUP_CONCURRENCY": &c.Cleanup.Concurrency,
+	} {
+		if v, ok := os.LookupEnv(env); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return i18n.Errorf("config.env_invalid", env, err)
+			}
+			*target = n
+		}
+	}
+	if v, ok := os.LookupEnv("DEVOPS_TOOL_CLEANUP_DRY_RUN"); ok {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			return i18n.Errorf("config.env_invalid", "DEVOPS_TOOL_CLEANUP_DRY_RUN", err)
+		}
+		c.Cleanup.DryRun = &dryRun
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"devops_tools/internal/api"
+	"devops_tools/internal/cluster"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testConfig = `
+output: json
+qps: 50
+cleanup:
+  concurrency: 8
+  reasons: [Available, PVCMissing]
+currentProfile: dev
+profiles:
+  dev:
+    context: dev-cluster
+  prod-shanghai:
+    context: prod-sh
+    cleanup:
+      dryRun: true
+`
+
+func TestLoadAndApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DEVOPS_TOOL_QPS", "80")
+
+	cfg, err := Load(path, "prod-shanghai")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Context != "prod-sh" || cfg.Output != "json" || cfg.QPS != 80 || cfg.Cleanup.Concurrency != 8 {
+		t.Errorf("unexpected settings: %+v", cfg.Settings)
+	}
+	if cfg.Cleanup.DryRun == nil || !*cfg.Cleanup.DryRun {
+		t.Errorf("profile dryRun not applied")
+	}
+	if errs := cfg.Validate(); len(errs) > 0 {
+		t.Errorf("Validate() = %v", errs)
+	}
+
+	var concurrency int
+	var dryRun bool
+	var reasons []string
+	cmd := &cobra.Command{Use: "clean-storage"}
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "")
+	cmd.Flags().StringSliceVar(&reasons, "reason", nil, "")
+	// 命令行指定的 flag 优先于配置
+	if err := cmd.Flags().Set("concurrency", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Apply(cmd); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if concurrency != 2 || !dryRun || len(reasons) != 2 || reasons[1] != string(cluster.ReasonPVCMissing) {
+		t.Errorf("Apply() concurrency=%d dryRun=%v reasons=%v", concurrency, dryRun, reasons)
+	}
+}
+
+func TestSetValidates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := Set(path, "profiles.prod.cleanup.concurrency", "6"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	cfg, err := Load(path, "prod")
+	if err != nil || cfg.Cleanup.Concurrency != 6 {
+		t.Fatalf("Load() = %+v, %v", cfg, err)
+	}
+	if err := Set(path, "cleanup.reasons", "[Unknown]"); err == nil {
+		t.Error("Set() should reject unknown reasons")
+	}
+	if err := Set(path, "outputs", "json"); err == nil {
+		t.Error("Set() should reject unknown keys")
+	}
+}
+
+func TestClusterRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `
+clusters:
+  standard:
+    kubeconfig: ~/.kube/standard
+    description: 标准环境
+  dr:
+    context: dr-admin
+    server: ftp://dr
+profiles:
+  dr:
+    clusterSign: dr
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path, "dr")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.ClusterSign != "dr" || cfg.Clusters["standard"].Kubeconfig != "~/.kube/standard" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if errs := cfg.Validate(); len(errs) != 1 {
+		t.Errorf("Validate() = %v, want one invalid server error", errs)
+	}
+
+	var sign string
+	cmd := &cobra.Command{Use: "get-pv"}
+	cmd.Flags().StringVar(&sign, "cluster-sign", "", "")
+	if err := cfg.Apply(cmd); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if sign != "dr" || api.Clusters["dr"].Context != "dr-admin" {
+		t.Errorf("Apply() cluster-sign = %q, registry = %v", sign, api.Clusters)
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"devops_tools/internal/i18n"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// Set 修改配置文件中以 . 分隔的 key，例如 cleanup.concurrency 或 profiles.prod.context。
+// value 按 YAML 解析，因此 3、true、[a, b] 会保存为对应类型。文件不存在时创建
+func Set(path, key, value string) error {
+	doc := map[string]interface{}{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return i18n.Errorf("config.parse_failed", path, err)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		parsed = value
+	}
+	keys := strings.Split(key, ".")
+	node := doc
+	for _, k := range keys[:len(keys)-1] {
+		child, ok := node[k].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			node[k] = child
+		}
+		node = child
+	}
+	node[keys[len(keys)-1]] = parsed
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	// 写入前确认结果仍是合法配置
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(out, cfg); err != nil {
+		return fmt.Errorf("%s: %v", key, err)
+	}
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return errs[0]
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
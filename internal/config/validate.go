@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bytes"
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/i18n"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+var knownReasons = map[cluster.CleanupReason]bool{
+	cluster.ReasonUnusedStorageClass: true,
+	cluster.ReasonAvailable:          true,
+	cluster.ReasonReleasedNoClaim:    true,
+	cluster.ReasonPVCMissing:         true,
+	cluster.ReasonUIDMismatch:        true,
+	cluster.ReasonNodeMissing:        true,
+}
+
+// ValidateFile 严格解析配置文件（不允许未知字段）并检查取值
+func ValidateFile(path string) []error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []error{i18n.Errorf("config.read_failed", path, err)}
+	}
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return []error{i18n.Errorf("config.parse_failed", path, err)}
+	}
+	return cfg.Validate()
+}
+
+// Validate 检查顶层配置和所有 profile，返回全部问题
+func (c *Config) Validate() []error {
+	errs := c.Settings.validate("")
+	if c.CurrentProfile != "" {
+		if _, ok := c.Profiles[c.CurrentProfile]; !ok {
+			errs = append(errs, fmt.Errorf("currentProfile: %w", i18n.Errorf("config.profile_missing", c.CurrentProfile)))
+		}
+	}
+	for sign, cl := range c.Clusters {
+		if cl.Server != "" && !strings.HasPrefix(cl.Server, "https://") && !strings.HasPrefix(cl.Server, "http://") {
+			errs = append(errs, fmt.Errorf("clusters.%s.server: %w", sign, i18n.Errorf("config.invalid_server", cl.Server)))
+		}
+	}
+	for name, raw := range c.Profiles {
+		var s Settings
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&s); err != nil {
+			errs = append(errs, fmt.Errorf("profiles.%s: %v", name, err))
+			continue
+		}
+		errs = append(errs, s.validate("profiles."+name+".")...)
+	}
+	return errs
+}
+
+func (s *Settings) validate(prefix string) []error {
+	var errs []error
+	invalid := func(field string, id string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s%s: %w", prefix, field, i18n.Errorf(id, args...)))
+	}
+	switch s.Output {
+	case "", "table", "json":
+	default:
+		invalid("output", "diff.unsupported", s.Output)
+	}
+	if s.Lang != "" {
+		if _, err := i18n.ParseLang(s.Lang); err != nil {
+			errs = append(errs, fmt.Errorf("%slang: %w", prefix, err))
+		}
+	}
+	if s.QPS < 0 {
+		invalid("qps", "config.negative")
+	}
+	if s.Burst < 0 {
+		invalid("burst", "config.negative")
+	}
+	if s.Cleanup.Concurrency < 0 {
+		invalid("cleanup.concurrency", "config.negative")
+	}
+	for _, r := range s.Cleanup.Reasons {
+		if !knownReasons[r] {
+			invalid("cleanup.reasons", "config.unknown_reason", r)
+		}
+	}
+	for i, d := range s.CSI {
+		if d.Driver == "" {
+			invalid(fmt.Sprintf("csi[%d].driver", i), "config.required")
+		}
+	}
+	return errs
+}
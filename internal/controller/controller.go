@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/i18n"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// PauseAnnotation 控制器所在命名空间带有该注解且值为 "true" 时跳过所有清理
+const PauseAnnotation = "devops-tool/cleanup-paused"
+
+// Options 控制器运行参数
+type Options struct {
+	// Schedule 内置清理策略的 5 段 cron 表达式，为空时只执行 StorageCleanupPolicy。
+	// 内置策略的 dry-run 和清理原因由 Clean 指定
+	Schedule string
+	// PolicySyncPeriod 检查 StorageCleanupPolicy 是否到期的间隔，为 0 时不处理策略对象
+	PolicySyncPeriod time.Duration
+	// Namespace 存放 Lease 以及读取暂停注解的命名空间
+	Namespace string
+	LeaseName string
+	// Identity 参与选主的实例标识，通常为 Pod 名称
+	Identity   string
+	HealthAddr string
+	Clean      cluster.CleanOptions
+}
+
+// Controller 按计划执行存储清理，多副本部署时通过 Lease 选主保证只有一个实例工作
+type Controller struct {
+	client   kubernetes.Interface
+	dynamic  dynamic.Interface
+	opts     Options
+	schedule cron.Schedule
+	recorder record.EventRecorder
+	ready    atomic.Bool
+	// lastRun 按 UID 记录策略最近一次执行时间，写 status 失败时仍能按计划判断是否到期，
+	// 只在 loop 所在的 goroutine 中访问
+	lastRun map[types.UID]time.Time
+}
+
+func New(client kubernetes.Interface, dynamicClient dynamic.Interface, opts Options) (*Controller, error) {
+	var schedule cron.Schedule
+	if opts.Schedule != "" {
+		var err error
+		schedule, err = cron.ParseStandard(opts.Schedule)
+		if err != nil {
+			return nil, apperr.ValidationError(i18n.Errorf("schedule.invalid", opts.Schedule, err))
+		}
+	}
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	recorder := broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "devops-tool-controller"})
+	opts.Clean.Recorder = recorder
+	return &Controller{client: client, dynamic: dynamicClient, opts: opts, schedule: schedule, recorder: recorder}, nil
+}
+
+// Run 启动健康检查服务并参与选主，成为 leader 后按计划执行清理，直到 ctx 结束或失去 leader
+func (c *Controller) Run(ctx context.Context) error {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metaV1.ObjectMeta{Name: c.opts.LeaseName, Namespace: c.opts.Namespace},
+		Client:     c.client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: c.opts.Identity},
+	}
+	watchdog := leaderelection.NewLeaderHealthzAdaptor(20 * time.Second)
+	go c.serveHealth(watchdog)
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   15 * time.Second,
+		RenewDeadline:   10 * time.Second,
+		RetryPeriod:     2 * time.Second,
+		ReleaseOnCancel: true,
+		WatchDog:        watchdog,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: c.loop,
+			OnStoppedLeading: func() {
+				log.Print(i18n.T("controller.stopped", c.opts.Identity))
+			},
+			OnNewLeader: func(identity string) {
+				log.Print(i18n.T("controller.leader", identity))
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	c.ready.Store(true)
+	elector.Run(ctx)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return i18n.Errorf("controller.lost_leader")
+}
+
+// loop 成为 leader 后按 cron 计划执行内置清理，并周期检查 StorageCleanupPolicy
+func (c *Controller) loop(ctx context.Context) {
+	log.Print(i18n.T("controller.started", c.opts.Identity, c.opts.Schedule))
+	var policyTick <-chan time.Time
+	if c.opts.PolicySyncPeriod > 0 {
+		ticker := time.NewTicker(c.opts.PolicySyncPeriod)
+		defer ticker.Stop()
+		policyTick = ticker.C
+	}
+	for {
+		var scheduled <-chan time.Time
+		if c.schedule != nil {
+			next := c.schedule.Next(time.Now())
+			log.Print(i18n.T("controller.next", next.Format(time.RFC3339)))
+			scheduled = time.After(time.Until(next))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-scheduled:
+			if err := c.Reconcile(ctx); err != nil {
+				log.Print(i18n.T("controller.cleanup_failed", err))
+			}
+		case <-policyTick:
+			if err := c.ReconcilePolicies(ctx); err != nil {
+				log.Print(i18n.T("controller.policy_failed", err))
+			}
+		}
+	}
+}
+
+// Reconcile 执行一次清理：检查暂停注解，拉取快照，按清理策略删除资源
+func (c *Controller) Reconcile(ctx context.Context) error {
+	paused, err := c.paused(ctx)
+	if err != nil {
+		return err
+	}
+	if paused {
+		log.Print(i18n.T("controller.paused", PauseAnnotation, c.opts.Namespace))
+		return nil
+	}
+	snap, err := cluster.LoadSnapshot(ctx, c.client)
+	if err != nil {
+		return err
+	}
+	return cluster.CleanStorageResources(ctx, c.client, snap, c.opts.Clean)
+}
+
+func (c *Controller) paused(ctx context.Context) (bool, error) {
+	ns, err := c.client.CoreV1().Namespaces().Get(ctx, c.opts.Namespace, metaV1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	return ns.Annotations[PauseAnnotation] == "true", nil
+}
+
+// serveHealth /healthz 检查 leader 续约是否卡住，/readyz 在完成初始化后返回 200
+func (c *Controller) serveHealth(watchdog *leaderelection.HealthzAdaptor) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if err := watchdog.Check(r); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !c.ready.Load() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	if err := http.ListenAndServe(c.opts.HealthAddr, mux); err != nil {
+		log.Print(i18n.T("controller.health_failed", err))
+	}
+}
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"context"
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/policy"
+	"errors"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestClient 返回带控制器命名空间、一个未使用的 StorageClass 和一个 Available PV 的 fake clientset
+func newTestClient(paused bool) *fake.Clientset {
+	ns := &corev1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "ops"}}
+	if paused {
+		ns.Annotations = map[string]string{PauseAnnotation: "true"}
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metaV1.ObjectMeta{Name: "pv-1", CreationTimestamp: metaV1.NewTime(time.Now().Add(-time.Hour))},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			StorageClassName: "local",
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+	}
+	sc := &storagev1.StorageClass{ObjectMeta: metaV1.ObjectMeta{Name: "unused"}}
+	return fake.NewSimpleClientset(ns, pv, sc)
+}
+
+func newTestController(t *testing.T, client *fake.Clientset, objects ...runtime.Object) *Controller {
+	t.Helper()
+	logFile := cluster.LogFile
+	cluster.LogFile = filepath.Join(t.TempDir(), "clean.log")
+	t.Cleanup(func() { cluster.LogFile = logFile })
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{policy.GroupVersionResource: policy.Kind + "List"}, objects...)
+	return &Controller{
+		client:  client,
+		dynamic: dynamicClient,
+		opts: Options{
+			Namespace: "ops",
+			Clean:     cluster.CleanOptions{BackupDir: t.TempDir(), Concurrency: 2},
+		},
+	}
+}
+
+func countPVs(t *testing.T, client *fake.Clientset) int {
+	t.Helper()
+	pvs, err := client.CoreV1().PersistentVolumes().List(context.Background(), metaV1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(pvs.Items)
+}
+
+func TestReconcile(t *testing.T) {
+	client := newTestClient(false)
+	c := newTestController(t, client)
+	if err := c.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if n := countPVs(t, client); n != 0 {
+		t.Errorf("%d PVs left after Reconcile, want 0", n)
+	}
+	if scs, _ := client.StorageV1().StorageClasses().List(context.Background(), metaV1.ListOptions{}); len(scs.Items) != 0 {
+		t.Errorf("%d StorageClasses left after Reconcile, want 0", len(scs.Items))
+	}
+}
+
+func TestReconcileDryRunAndReasons(t *testing.T) {
+	client := newTestClient(false)
+	c := newTestController(t, client)
+	c.opts.Clean.DryRun = true
+	if err := c.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if n := countPVs(t, client); n != 1 {
+		t.Errorf("dry-run Reconcile deleted PVs, %d left, want 1", n)
+	}
+
+	// 只清理 Available，未使用的 StorageClass 保留
+	c.opts.Clean.DryRun = false
+	c.opts.Clean.Reasons = []cluster.CleanupReason{cluster.ReasonAvailable}
+	if err := c.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if n := countPVs(t, client); n != 0 {
+		t.Errorf("%d PVs left, want 0", n)
+	}
+	if scs, _ := client.StorageV1().StorageClasses().List(context.Background(), metaV1.ListOptions{}); len(scs.Items) != 1 {
+		t.Errorf("%d StorageClasses left, want 1", len(scs.Items))
+	}
+}
+
+func TestReconcilePaused(t *testing.T) {
+	client := newTestClient(true)
+	c := newTestController(t, client)
+	if err := c.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "delete" || action.GetVerb() == "list" {
+			t.Errorf("paused Reconcile made %s %s request", action.GetVerb(), action.GetResource().Resource)
+		}
+	}
+}
+
+func TestReconcileCancelled(t *testing.T) {
+	client := newTestClient(false)
+	c := newTestController(t, client)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// fake clientset 不检查 ctx，快照可以正常加载，删除阶段应当全部跳过
+	if err := c.Reconcile(ctx); err == nil {
+		t.Error("Reconcile() with cancelled ctx should report skipped deletions")
+	}
+	if n := countPVs(t, client); n != 1 {
+		t.Errorf("%d PVs left after cancelled Reconcile, want 1", n)
+	}
+}
+
+func testPolicy(t *testing.T) runtime.Object {
+	t.Helper()
+	p := &policy.StorageCleanupPolicy{
+		TypeMeta:   metaV1.TypeMeta{APIVersion: policy.Group + "/" + policy.Version, Kind: policy.Kind},
+		ObjectMeta: metaV1.ObjectMeta{Name: "available", UID: "uid-1", CreationTimestamp: metaV1.NewTime(time.Now().Add(-time.Hour))},
+		Spec: policy.StorageCleanupPolicySpec{
+			Reasons:  []cluster.CleanupReason{cluster.ReasonAvailable},
+			Schedule: "* * * * *",
+			Backup:   policy.BackupSink{Dir: t.TempDir()},
+		},
+	}
+	u, err := p.ToUnstructured()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func getPolicy(t *testing.T, c *Controller) *policy.StorageCleanupPolicy {
+	t.Helper()
+	u, err := c.dynamic.Resource(policy.GroupVersionResource).Get(context.Background(), "available", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := policy.FromUnstructured(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestReconcilePolicies(t *testing.T) {
+	client := newTestClient(false)
+	c := newTestController(t, client, testPolicy(t))
+	if err := c.ReconcilePolicies(context.Background()); err != nil {
+		t.Fatalf("ReconcilePolicies() error = %v", err)
+	}
+	if n := countPVs(t, client); n != 0 {
+		t.Errorf("%d PVs left after ReconcilePolicies, want 0", n)
+	}
+	// 策略只允许 Available，未使用的 StorageClass 不会被删除
+	if scs, _ := client.StorageV1().StorageClasses().List(context.Background(), metaV1.ListOptions{}); len(scs.Items) != 1 {
+		t.Errorf("%d StorageClasses left, want 1", len(scs.Items))
+	}
+	p := getPolicy(t, c)
+	if p.Status.LastRunTime == nil || p.Status.Candidates != 1 || p.Status.Deleted != 1 {
+		t.Errorf("status = %+v", p.Status)
+	}
+}
+
+func TestReconcilePoliciesPaused(t *testing.T) {
+	client := newTestClient(true)
+	c := newTestController(t, client, testPolicy(t))
+	if err := c.ReconcilePolicies(context.Background()); err != nil {
+		t.Fatalf("ReconcilePolicies() error = %v", err)
+	}
+	if n := countPVs(t, client); n != 1 {
+		t.Errorf("%d PVs left after paused ReconcilePolicies, want 1", n)
+	}
+	if p := getPolicy(t, c); p.Status.LastRunTime != nil {
+		t.Errorf("paused ReconcilePolicies updated status: %+v", p.Status)
+	}
+}
+
+func TestReconcilePoliciesStatusFailure(t *testing.T) {
+	client := newTestClient(false)
+	c := newTestController(t, client, testPolicy(t))
+	c.dynamic.(*dynamicfake.FakeDynamicClient).PrependReactor("update", "storagecleanuppolicies", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("status write failed")
+	})
+	if err := c.ReconcilePolicies(context.Background()); err != nil {
+		t.Fatalf("ReconcilePolicies() error = %v", err)
+	}
+	if n := countPVs(t, client); n != 0 {
+		t.Fatalf("%d PVs left after first run, want 0", n)
+	}
+
+	// status 未写入，下一次同步仍应按内存中的执行时间判断，不会立即再次执行
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metaV1.ObjectMeta{Name: "pv-2", CreationTimestamp: metaV1.NewTime(time.Now().Add(-time.Hour))},
+		Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+	}
+	if _, err := client.CoreV1().PersistentVolumes().Create(context.Background(), pv, metaV1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ReconcilePolicies(context.Background()); err != nil {
+		t.Fatalf("ReconcilePolicies() error = %v", err)
+	}
+	if n := countPVs(t, client); n != 1 {
+		t.Errorf("policy ran again before its next schedule, %d PVs left, want 1", n)
+	}
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/i18n"
+	"devops_tools/internal/policy"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"log"
+	"time"
+)
+
+// ReconcilePolicies 执行所有到期的 StorageCleanupPolicy，并把结果写回 status
+func (c *Controller) ReconcilePolicies(ctx context.Context) error {
+	list, err := c.dynamic.Resource(policy.GroupVersionResource).List(ctx, metaV1.ListOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// CRD 未安装时不视为错误
+			return nil
+		}
+		return err
+	}
+	if len(list.Items) == 0 {
+		return nil
+	}
+	paused, err := c.paused(ctx)
+	if err != nil {
+		return err
+	}
+	if paused {
+		log.Print(i18n.T("controller.paused", PauseAnnotation, c.opts.Namespace))
+		return nil
+	}
+
+	now := time.Now()
+	var snap *cluster.Snapshot
+	for i := range list.Items {
+		p, err := policy.FromUnstructured(&list.Items[i])
+		if err != nil {
+			log.Print(i18n.T("policy.decode_failed", list.Items[i].GetName(), err))
+			continue
+		}
+		c.restoreLastRun(p)
+		due, err := policyDue(p, now)
+		if err != nil {
+			c.updatePolicyStatus(ctx, p, now, cluster.CleanupResult{}, err.Error())
+			continue
+		}
+		if !due {
+			continue
+		}
+		// 同一轮内到期的策略共用一份快照
+		if snap == nil {
+			if snap, err = cluster.LoadSnapshot(ctx, c.client); err != nil {
+				return err
+			}
+		}
+		result, runErr := c.runPolicy(ctx, p, snap, now)
+		msg := ""
+		if runErr != nil {
+			msg = runErr.Error()
+		}
+		c.updatePolicyStatus(ctx, p, now, result, msg)
+	}
+	return nil
+}
+
+// runPolicy 按策略筛选候选并执行删除
+func (c *Controller) runPolicy(ctx context.Context, p *policy.StorageCleanupPolicy, snap *cluster.Snapshot, now time.Time) (cluster.CleanupResult, error) {
+	candidates, err := p.Candidates(snap, now)
+	if err != nil {
+		return cluster.CleanupResult{}, err
+	}
+	opts := c.opts.Clean
+	opts.DryRun = p.Spec.DryRun
+	if p.Spec.Backup.Dir != "" {
+		opts.BackupDir = p.Spec.Backup.Dir
+	}
+	log.Print(i18n.T("policy.run", p.Name, len(candidates), p.Spec.DryRun))
+	result := cluster.DeleteCandidates(ctx, c.client, candidates, opts)
+	return result, result.Err()
+}
+
+// policyDue 判断策略在 now 时是否到了执行时间，从上次执行或创建时间开始计算
+func policyDue(p *policy.StorageCleanupPolicy, now time.Time) (bool, error) {
+	schedule, err := cron.ParseStandard(p.Spec.Schedule)
+	if err != nil {
+		return false, i18n.Errorf("schedule.invalid", p.Spec.Schedule, err)
+	}
+	from := p.CreationTimestamp.Time
+	if p.Status.LastRunTime != nil {
+		from = p.Status.LastRunTime.Time
+	}
+	return !schedule.Next(from).After(now), nil
+}
+
+// restoreLastRun status 中的执行时间早于内存记录时（上次写 status 失败），使用内存中的时间，
+// 避免策略在每个 PolicySyncPeriod 都被重新执行
+func (c *Controller) restoreLastRun(p *policy.StorageCleanupPolicy) {
+	last, ok := c.lastRun[p.UID]
+	if !ok {
+		return
+	}
+	if p.Status.LastRunTime == nil || p.Status.LastRunTime.Time.Before(last) {
+		runTime := metaV1.NewTime(last)
+		p.Status.LastRunTime = &runTime
+	}
+}
+
+func (c *Controller) updatePolicyStatus(ctx context.Context, p *policy.StorageCleanupPolicy, now time.Time, result cluster.CleanupResult, msg string) {
+	if c.lastRun == nil {
+		c.lastRun = make(map[types.UID]time.Time)
+	}
+	c.lastRun[p.UID] = now
+	runTime := metaV1.NewTime(now)
+	p.Status = policy.StorageCleanupPolicyStatus{
+		ObservedGeneration: p.Generation,
+		LastRunTime:        &runTime,
+		Candidates:         result.Candidates,
+		Deleted:            result.Deleted,
+		Failures:           result.Failed,
+		Message:            msg,
+	}
+	u, err := p.ToUnstructured()
+	if err != nil {
+		log.Print(i18n.T("policy.encode_failed", p.Name, err))
+		return
+	}
+	if _, err := c.dynamic.Resource(policy.GroupVersionResource).UpdateStatus(ctx, u, metaV1.UpdateOptions{}); err != nil {
+		log.Print(i18n.T("policy.status_failed", p.Name, err))
+	}
+}
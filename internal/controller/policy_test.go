@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"devops_tools/internal/policy"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestPolicyDue(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 30, 0, 0, time.Local)
+	lastRun := metaV1.NewTime(time.Date(2026, 1, 2, 2, 0, 0, 0, time.Local))
+	cases := []struct {
+		name    string
+		lastRun *metaV1.Time
+		now     time.Time
+		want    bool
+	}{
+		{"never run, before first slot", nil, time.Date(2026, 1, 1, 1, 0, 0, 0, time.Local), false},
+		{"never run, after first slot", nil, time.Date(2026, 1, 1, 2, 0, 0, 0, time.Local), true},
+		{"ran today", &lastRun, time.Date(2026, 1, 2, 23, 0, 0, 0, time.Local), false},
+		{"ran yesterday", &lastRun, time.Date(2026, 1, 3, 2, 1, 0, 0, time.Local), true},
+	}
+	for _, tc := range cases {
+		p := &policy.StorageCleanupPolicy{
+			ObjectMeta: metaV1.ObjectMeta{CreationTimestamp: metaV1.NewTime(created)},
+			Spec:       policy.StorageCleanupPolicySpec{Schedule: "0 2 * * *"},
+			Status:     policy.StorageCleanupPolicyStatus{LastRunTime: tc.lastRun},
+		}
+		got, err := policyDue(p, tc.now)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: policyDue() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if _, err := policyDue(&policy.StorageCleanupPolicy{Spec: policy.StorageCleanupPolicySpec{Schedule: "bad"}}, time.Now()); err == nil {
+		t.Error("expected error for invalid schedule")
+	}
+}
@@ -0,0 +1,128 @@
+package exporter
+
+import (
+	"context"
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/i18n"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	pvInfoDesc = prometheus.NewDesc("devops_pv_info",
+		"PersistentVolume information, value is always 1.",
+		[]string{"name", "sc", "type", "phase", "node"}, nil)
+	pvCapacityDesc = prometheus.NewDesc("devops_pv_capacity_bytes",
+		"PersistentVolume capacity in bytes.",
+		[]string{"name", "sc"}, nil)
+	pvOrphanDesc = prometheus.NewDesc("devops_pv_orphan",
+		"PersistentVolume considered orphaned by the cleanup analysis, value is always 1.",
+		[]string{"name", "sc", "reason"}, nil)
+	localPVNodeMissingDesc = prometheus.NewDesc("devops_local_pv_node_missing",
+		"Local PersistentVolume whose bound node no longer exists, value is always 1.",
+		[]string{"name", "node"}, nil)
+	scNamespaceBindingsDesc = prometheus.NewDesc("devops_sc_namespace_bindings",
+		"Number of namespaces bound to the StorageClass through the dophin/storage annotation.",
+		[]string{"sc"}, nil)
+	lastRefreshDesc = prometheus.NewDesc("devops_exporter_last_refresh_timestamp_seconds",
+		"Unix time of the last successful inventory refresh.", nil, nil)
+	refreshErrorsDesc = prometheus.NewDesc("devops_exporter_refresh_errors_total",
+		"Number of failed inventory refreshes.", nil, nil)
+)
+
+// Exporter 周期性拉取集群快照，并在抓取时将存储分析结果转换为指标
+type Exporter struct {
+	client kubernetes.Interface
+
+	mu            sync.RWMutex
+	snap          *cluster.Snapshot
+	lastRefresh   time.Time
+	refreshErrors float64
+}
+
+func New(client kubernetes.Interface) *Exporter {
+	return &Exporter{client: client}
+}
+
+// Refresh 重新拉取一次快照，失败时保留上一次的结果
+func (e *Exporter) Refresh(ctx context.Context) error {
+	snap, err := cluster.LoadSnapshot(ctx, e.client)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		e.refreshErrors++
+		return err
+	}
+	e.snap = snap
+	e.lastRefresh = time.Now()
+	return nil
+}
+
+// Run 按 interval 刷新快照直到 ctx 结束
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := e.Refresh(ctx); err != nil {
+			log.Print(i18n.T("exporter.refresh_failed", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Handler 返回只包含存储指标的 /metrics 处理器
+func (e *Exporter) Handler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(e)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pvInfoDesc
+	ch <- pvCapacityDesc
+	ch <- pvOrphanDesc
+	ch <- localPVNodeMissingDesc
+	ch <- scNamespaceBindingsDesc
+	ch <- lastRefreshDesc
+	ch <- refreshErrorsDesc
+}
+
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	ch <- prometheus.MustNewConstMetric(refreshErrorsDesc, prometheus.CounterValue, e.refreshErrors)
+	if e.snap == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(lastRefreshDesc, prometheus.GaugeValue, float64(e.lastRefresh.Unix()))
+
+	for _, pv := range e.snap.PersistentVolumeInfos() {
+		node := strings.Join(pv.Nodes, ",")
+		ch <- prometheus.MustNewConstMetric(pvInfoDesc, prometheus.GaugeValue, 1, pv.Name, pv.StorageClass, pv.Type, string(pv.Status), node)
+		ch <- prometheus.MustNewConstMetric(pvCapacityDesc, prometheus.GaugeValue, float64(pv.CapacityBytes), pv.Name, pv.StorageClass)
+		if pv.NodeIsExist == "no" {
+			ch <- prometheus.MustNewConstMetric(localPVNodeMissingDesc, prometheus.GaugeValue, 1, pv.Name, node)
+		}
+	}
+	for _, c := range e.snap.OrphanCandidates() {
+		sc := ""
+		if pv, ok := c.Object.(*corev1.PersistentVolume); ok {
+			sc = pv.Spec.StorageClassName
+		}
+		ch <- prometheus.MustNewConstMetric(pvOrphanDesc, prometheus.GaugeValue, 1, c.Name, sc, string(c.Reason))
+	}
+	for _, sc := range e.snap.StorageClasses {
+		ch <- prometheus.MustNewConstMetric(scNamespaceBindingsDesc, prometheus.GaugeValue, float64(len(e.snap.NamespacesBoundTo(sc.Name))), sc.Name)
+	}
+}
@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"context"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExporterScrape(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&storagev1.StorageClass{ObjectMeta: metaV1.ObjectMeta{Name: "local"}},
+		&corev1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "tenant", Annotations: map[string]string{"dophin/storage": "local"}}},
+		&corev1.PersistentVolume{
+			ObjectMeta: metaV1.ObjectMeta{Name: "pv-1"},
+			Spec: corev1.PersistentVolumeSpec{
+				StorageClassName: "local",
+				Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					Local: &corev1.LocalVolumeSource{Path: "/data"},
+				},
+				NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "kubernetes.io/hostname", Operator: corev1.NodeSelectorOpIn, Values: []string{"gone"}},
+					}}},
+				}},
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable},
+		},
+	)
+	e := New(client)
+	if err := e.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	srv := httptest.NewServer(e.Handler())
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("scrape error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	for _, want := range []string{
+		`devops_pv_info{name="pv-1",node="gone",phase="Available",sc="local",type="local"} 1`,
+		`devops_pv_capacity_bytes{name="pv-1",sc="local"} 1.073741824e+09`,
+		`devops_pv_orphan{name="pv-1",reason="Available",sc="local"} 1`,
+		`devops_local_pv_node_missing{name="pv-1",node="gone"} 1`,
+		`devops_sc_namespace_bindings{sc="local"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics missing %q\n%s", want, body)
+		}
+	}
+}
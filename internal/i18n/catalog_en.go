@@ -0,0 +1,164 @@
+package i18n
+
+// en 英文消息。命令帮助和表格列名以代码中的英文为准，不在这里重复
+var en = map[string]string{
+	// 文件输出
+	"sc.file_written":                 "StorageClass data written to file: %s\n",
+	"pv.file_written":                 "PersistentVolume data written to file: %s\n",
+	"ns.file_written":                 "Namespace data written to file: %s\n",
+	"node.file_written":               "Node data written to file: %s\n",
+	"workload.file_written":           "Workload data written to file: %s\n",
+	"workload.unknown_kind":           "unknown workload kind %s, expected Deployment, StatefulSet, DaemonSet, CronJob or Job",
+	"image.file_written":              "Image data written to file: %s\n",
+	"rightsizing.file_written":        "Rightsizing data written to file: %s\n",
+	"rightsizing.metrics_unavailable": "metrics.k8s.io is not available, is metrics-server installed? %v",
+	"rightsizing.sampled":             "Collected sample %d/%d\n",
+	"rightsizing.saving":              "Requests that can be released: cpu %s, memory %s\n",
+	"rightsizing.invalid_headroom":    "--headroom must not be negative, got %v",
+	"rightsizing.invalid_interval":    "--interval must be positive when --window is set, got %s",
+	"rightsizing.unmatched":           "Warning: usage of %d pods that no longer exist is not counted\n",
+	"plan.file_written":               "Cleanup plan written to file: %s\n",
+	"diff.file_written":               "Storage diff written to file: %s\n",
+	"report.file_written":             "Storage report written to file: %s\n",
+	"snapshot.saved":                  "Snapshot saved to directory: %s\n",
+	"progress":                        "%s: %d/%d processed, %d failed\n",
+	"progress.delete":                 "Deleting %s",
+	"log.open_failed":                 "Failed to open log file: %v\n",
+	"clean.start":                     "Starting storage cleanup...\n",
+	"clean.done":                      "Storage cleanup finished.\n",
+	"clean.mkdir_failed":              "Failed to create backup directory %s: %v",
+	"clean.backup_failed":             "Failed to back up %s %s: %v\n",
+	"clean.delete_failed":             "Failed to delete %s %s: %v\n",
+	"clean.deleted":                   "Deleted and backed up %s: %s\n",
+	"clean.partial":                   "%d of %d deletions failed",
+	"clean.interrupted":               "cleanup interrupted, %d of %d resources were not processed",
+	"clean.skipped":                   "Cleanup cancelled, skipped %d %s: %v\n",
+	"backup.meta_failed":              "failed to read object metadata: %v",
+	"backup.mkdir_failed":             "failed to create backup directory: %v",
+	"backup.create_failed":            "failed to create backup file: %v",
+	"backup.encode_failed":            "failed to serialize object: %v",
+
+	// 清理计划
+	"plan.sc_unused":          "Deleting unused StorageClass: %s\n",
+	"plan.pv_available":       "PV %s is Available, deleting with backup\n",
+	"plan.pv_released_no_ref": "PV %s is Released without ClaimRef, deleting\n",
+	"plan.pvc_missing":        "PVC %s/%s does not exist, deleting PV %s\n",
+	"plan.uid_mismatch":       "PVC %s/%s exists but its UID does not match, deleting PV %s\n",
+	"plan.pv_in_use":          "PV %s is in use by a PVC, skipping\n",
+	"plan.pv_skip_phase":      "PV %s is %s, skipping\n",
+	"plan.node_missing":       "Node %[2]s bound to PV %[1]s does not exist\n",
+
+	// 快照与报表
+	"snapshot.load_failed":     "failed to load snapshot file %s: %v",
+	"snapshot.mkdir_failed":    "failed to create snapshot directory: %v",
+	"snapshot.write_failed":    "failed to write snapshot file %s: %v",
+	"snapshot.gvk_failed":      "cannot get GVK from scheme: %v",
+	"report.template_read":     "failed to read report template: %v",
+	"report.template_parse":    "failed to parse report template: %v",
+	"report.unsupported":       "unsupported report format %q",
+	"report.file_required":     "--file is required for xlsx format",
+	"diff.unsupported":         "unsupported output format %q",
+	"install.unsupported_mode": "unsupported mode %q, must be one of cronjob, controller, exporter",
+	"install.invalid_size":     "invalid backup size %q: %v",
+	"schedule.invalid":         "invalid schedule %q: %v",
+	"policy.invalid_selector":  "invalid selector: %v",
+
+	// 集群连接与权限
+	"api.no_config":             "can't find config: %v; %v",
+	"api.unknown_cluster_sign":  "unknown cluster sign %q, registered signs: %s",
+	"api.cluster_sign_failed":   "failed to load kubeconfig for cluster sign %q: %v",
+	"api.clientset_failed":      "can't create clientset: %v",
+	"api.list_ns_failed":        "can't list namespaces: %v",
+	"api.connect_failed":        "can't connect to cluster: %v",
+	"preflight.check_failed":    "failed to check permission %s %s: %v",
+	"preflight.failed_header":   "Preflight failed, the current user is missing these permissions (yes: allowed, NO: missing, -: not required):",
+	"preflight.missing":         "missing %d permissions: %s",
+	"doctor.connectivity_ok":    "API server is reachable",
+	"doctor.permissions_ok":     "all %d permissions granted",
+	"doctor.backup_writable":    "%s is writable",
+	"doctor.check_failed":       "%s check failed: %w",
+	"exporter.refresh_failed":   "refresh storage inventory failed: %v",
+	"exporter.listening":        "exporter listening on %s",
+	"controller.stopped":        "%s stopped leading",
+	"controller.leader":         "current leader: %s",
+	"controller.lost_leader":    "lost leader election",
+	"controller.started":        "%s started leading, schedule %q",
+	"controller.next":           "next cleanup at %s",
+	"controller.cleanup_failed": "cleanup failed: %v",
+	"controller.policy_failed":  "reconcile StorageCleanupPolicy failed: %v",
+	"controller.paused":         "cleanup paused by %s annotation on namespace %s",
+	"controller.health_failed":  "health server: %v",
+	"policy.decode_failed":      "decode StorageCleanupPolicy %s: %v",
+	"policy.run":                "StorageCleanupPolicy %s: %d candidates, dryRun=%v",
+	"policy.encode_failed":      "encode StorageCleanupPolicy %s: %v",
+	"policy.status_failed":      "update StorageCleanupPolicy %s status: %v",
+	"cleanup.failed":            "cleanup failed: %w",
+
+	// 配置文件
+	"config.read_failed":          "failed to read config file %s: %v",
+	"config.parse_failed":         "failed to parse config file %s: %v",
+	"config.profile_missing":      "profile %q does not exist",
+	"config.profile_parse_failed": "failed to parse profile %q: %v",
+	"config.env_invalid":          "invalid %s: %v",
+	"config.negative":             "must not be negative",
+	"config.unknown_reason":       "unknown cleanup reason %q",
+	"config.required":             "must not be empty",
+	"config.valid":                "%s is valid\n",
+	"config.no_files":             "no config file found, defaults are in effect\n",
+	"config.invalid_server":       "must be an http:// or https:// URL, got %q",
+	"config.invalid_files":        "%d config files are invalid",
+
+	// MySQL 备份恢复
+	"mysql.instance_not_found":       "MySQL instance %s not found: no StatefulSet or pod with this name",
+	"mysql.no_ready_pod":             "no running and ready pod found for MySQL instance %s",
+	"mysql.list_db_failed":           "failed to list databases: %w",
+	"mysql.dump_failed":              "dump database %s failed: %v",
+	"mysql.dump_exists":              "%s already exists",
+	"mysql.not_dump":                 "%s is not a devops-tool MySQL dump: %v",
+	"mysql.restore_failed":           "restore %s into %s failed: %w",
+	"instance.restored":              "Restored %s into %s\n",
+	"instance.flag_required":         "--%s is required",
+	"mysql.database_required":        "--database is required to pick the latest backup when --file is not set",
+	"mysql.src_mismatch":             "%s was taken from %s, not %s",
+	"mysql.no_backup":                "no backup of database %s from %s found under %s",
+	"mysql.invalid_samples":          "--sample-tables must be at least 1, got %d",
+	"mysql.invalid_password_env":     "invalid --password-env %q, must be an environment variable name",
+	"mysql.verify_not_ready":         "temporary MySQL instance did not become ready: %v",
+	"mysql.verify_pod_created":       "Created temporary MySQL pod %s/%s\n",
+	"mysql.verify_pod_exited":        "temporary MySQL pod exited with phase %s",
+	"mysql.verify_kept":              "Kept temporary MySQL pod %s/%s, delete it when done\n",
+	"mysql.verify_cleanup_failed":    "failed to delete %s: %v\n",
+	"mysql.verify_no_tables":         "database %s has no tables after restore",
+	"mysql.verify_unexpected_output": "unexpected output: %d row counts and %d checksums for %d tables",
+	"mysql.verify_checksum_failed":   "CHECKSUM TABLE failed on table %s",
+	"mysql.verify_record_failed":     "failed to record verification in the backup catalog: %v\n",
+	"mysql.verify_failed":            "verification of %s failed: %w",
+	"mysql.verify_passed":            "Verified %s: %d tables restored in %s\n",
+
+	// TiDB 备份恢复
+	"tidb.service_not_found": "service %s/%s not found, is the TiDB cluster name correct?",
+	"tidb.invalid_tool":      "invalid tool %q, must be dumpling or br",
+	"tidb.invalid_secret":    "invalid --password-secret %q, must be <secret>/<key>",
+	"tidb.pvc_required":      "--pvc is required for %s to write or read the backup",
+	"tidb.job_create_failed": "failed to create job %s: %w",
+	"tidb.job_created":       "Created job %s/%s, waiting for it to finish\n",
+	"tidb.job_wait_failed":   "failed waiting for job %s: %w",
+	"tidb.job_failed":        "job %s/%s failed, last log lines:\n%s",
+	"tidb.meta_missing":      "job %s/%s finished without printing the backup metadata",
+	"tidb.record_failed":     "failed to record backup %s: %w",
+	"tidb.no_backup":         "no recorded backup of %s matches %q",
+	"tidb.catalog_not_dir":   "--catalog %s is not a directory, it must be the local mount of the backup PVC",
+
+	// 备份目录索引
+	"catalog.parse_failed":      "failed to parse backup catalog %s: %v",
+	"catalog.locked":            "backup catalog is locked by another process, remove %s if no backup is running",
+	"catalog.record_failed":     "failed to update backup catalog: %v",
+	"catalog.remove_failed":     "failed to delete %s: %v",
+	"catalog.policy_negative":   "--keep-daily, --keep-weekly and --keep-monthly must not be negative",
+	"catalog.policy_empty":      "at least one of --keep-daily, --keep-weekly and --keep-monthly must be positive",
+	"catalog.checksum_mismatch": "%s is corrupted: catalog checksum %s, file checksum %s",
+	"catalog.pruned":            "%d backups pruned\n",
+	"catalog.would_prune":       "%d backups would be pruned\n",
+	"catalog.entry_not_found":   "%s is not in the backup catalog under %s",
+	"catalog.outside_root":      "refusing to delete %s: not under backup root %s",
+}
@@ -0,0 +1,450 @@
+package i18n
+
+// zhCN 中文消息，包括命令帮助和表格列名
+var zhCN = map[string]string{
+	// 文件输出
+	"sc.file_written":                 "StorageClass 数据已写入文件: %s\n",
+	"pv.file_written":                 "PersistentVolume 数据已写入文件: %s\n",
+	"ns.file_written":                 "命名空间数据已写入文件: %s\n",
+	"node.file_written":               "节点数据已写入文件: %s\n",
+	"workload.file_written":           "工作负载数据已写入文件: %s\n",
+	"workload.unknown_kind":           "未知的工作负载类型 %s，可选 Deployment、StatefulSet、DaemonSet、CronJob 或 Job",
+	"image.file_written":              "镜像数据已写入文件: %s\n",
+	"rightsizing.file_written":        "资源推荐数据已写入文件: %s\n",
+	"rightsizing.metrics_unavailable": "metrics.k8s.io 不可用，请确认已部署 metrics-server: %v",
+	"rightsizing.sampled":             "已完成第 %d/%d 次采样\n",
+	"rightsizing.saving":              "按推荐值调整后可释放的请求: CPU %s，内存 %s\n",
+	"rightsizing.invalid_headroom":    "--headroom 不能为负数，当前为 %v",
+	"rightsizing.invalid_interval":    "指定 --window 时 --interval 必须大于 0，当前为 %s",
+	"rightsizing.unmatched":           "警告：%d 个已不存在的 Pod 的用量未计入推荐值\n",
+	"plan.file_written":               "清理计划已写入文件: %s\n",
+	"diff.file_written":               "存储差异已写入文件: %s\n",
+	"report.file_written":             "存储报表已写入文件: %s\n",
+	"snapshot.saved":                  "快照已保存到目录: %s\n",
+	"progress":                        "%s: %d/%d 已处理, %d 失败\n",
+	"progress.delete":                 "删除 %s",
+	"log.open_failed":                 "无法打开日志文件: %v\n",
+	"clean.start":                     "开始执行存储资源清理任务...\n",
+	"clean.done":                      "存储资源清理完成。\n",
+	"clean.mkdir_failed":              "创建备份目录失败%s: %v",
+	"clean.backup_failed":             "备份 %s %s 失败: %v\n",
+	"clean.delete_failed":             "删除 %s %s 失败: %v\n",
+	"clean.deleted":                   "成功删除并备份 %s: %s\n",
+	"clean.partial":                   "%[2]d 个资源中有 %[1]d 个删除失败",
+	"clean.interrupted":               "清理被中断，%[2]d 个资源中有 %[1]d 个未处理",
+	"clean.skipped":                   "清理已取消，跳过 %d 个 %s: %v\n",
+	"backup.meta_failed":              "获取对象元数据失败: %v",
+	"backup.mkdir_failed":             "创建备份目录失败: %v",
+	"backup.create_failed":            "创建备份文件失败: %v",
+	"backup.encode_failed":            "序列化资源对象失败: %v",
+
+	// 清理计划
+	"plan.sc_unused":          "准备删除未使用的 StorageClass: %s\n",
+	"plan.pv_available":       "PV %s 状态为 Available，准备删除并备份\n",
+	"plan.pv_released_no_ref": "PV %s 状态为 Released，但无 ClaimRef，直接删除\n",
+	"plan.pvc_missing":        "PVC %s/%s 不存在，准备删除 PV %s\n",
+	"plan.uid_mismatch":       "PVC %s/%s 存在，但 UID 不匹配，准备删除 PV %s\n",
+	"plan.pv_in_use":          "PV %s 正在被 PVC 使用，跳过删除\n",
+	"plan.pv_skip_phase":      "PV %s 状态为 %s，跳过删除\n",
+	"plan.node_missing":       "PV %s 绑定的节点 %s 不存在\n",
+
+	// 快照与报表
+	"snapshot.load_failed":     "加载快照文件 %s 失败: %v",
+	"snapshot.mkdir_failed":    "创建快照目录失败: %v",
+	"snapshot.write_failed":    "写入快照文件 %s 失败: %v",
+	"snapshot.gvk_failed":      "无法从 scheme 获取 GVK: %v",
+	"report.template_read":     "读取报表模板失败: %v",
+	"report.template_parse":    "解析报表模板失败: %v",
+	"report.unsupported":       "不支持的报表格式 %q",
+	"report.file_required":     "xlsx 格式必须指定 --file",
+	"diff.unsupported":         "不支持的输出格式 %q",
+	"install.unsupported_mode": "不支持的模式 %q，可选值为 cronjob、controller、exporter",
+	"install.invalid_size":     "备份容量 %q 不合法: %v",
+	"schedule.invalid":         "cron 表达式 %q 不合法: %v",
+	"policy.invalid_selector":  "selector 不合法: %v",
+
+	// 集群连接与权限
+	"api.no_config":             "找不到 kubeconfig: %v; %v",
+	"api.unknown_cluster_sign":  "未登记的集群标识 %q，已登记: %s",
+	"api.cluster_sign_failed":   "加载集群标识 %q 的 kubeconfig 失败: %v",
+	"api.clientset_failed":      "创建 clientset 失败: %v",
+	"api.list_ns_failed":        "无权限列出 namespace: %v",
+	"api.connect_failed":        "无法连接集群: %v",
+	"preflight.check_failed":    "检查权限 %s %s 失败: %v",
+	"preflight.failed_header":   "权限预检未通过，当前用户缺少以下权限 (yes: 已授权, NO: 缺失, -: 不需要):",
+	"preflight.missing":         "缺少 %d 项权限: %s",
+	"doctor.connectivity_ok":    "可以访问 API Server",
+	"doctor.permissions_ok":     "%d 项权限均已授权",
+	"doctor.backup_writable":    "%s 可写",
+	"doctor.check_failed":       "%s 检查未通过: %w",
+	"exporter.refresh_failed":   "刷新存储数据失败: %v",
+	"exporter.listening":        "exporter 监听地址 %s",
+	"controller.stopped":        "%s 不再是 leader",
+	"controller.leader":         "当前 leader: %s",
+	"controller.lost_leader":    "失去 leader 身份",
+	"controller.started":        "%s 成为 leader，清理计划 %q",
+	"controller.next":           "下次清理时间 %s",
+	"controller.cleanup_failed": "清理失败: %v",
+	"controller.policy_failed":  "处理 StorageCleanupPolicy 失败: %v",
+	"controller.paused":         "命名空间 %[2]s 带有 %[1]s 注解，暂停清理",
+	"controller.health_failed":  "健康检查服务异常: %v",
+	"policy.decode_failed":      "解析 StorageCleanupPolicy %s 失败: %v",
+	"policy.run":                "StorageCleanupPolicy %s: %d 个候选资源, dryRun=%v",
+	"policy.encode_failed":      "转换 StorageCleanupPolicy %s 失败: %v",
+	"policy.status_failed":      "更新 StorageCleanupPolicy %s 状态失败: %v",
+	"cleanup.failed":            "清理失败: %w",
+
+	// 配置文件
+	"config.read_failed":          "读取配置文件 %s 失败: %v",
+	"config.parse_failed":         "解析配置文件 %s 失败: %v",
+	"config.profile_missing":      "profile %q 不存在",
+	"config.profile_parse_failed": "解析 profile %q 失败: %v",
+	"config.env_invalid":          "%s 不合法: %v",
+	"config.negative":             "不能为负数",
+	"config.unknown_reason":       "未知的清理原因 %q",
+	"config.required":             "不能为空",
+	"config.valid":                "%s 校验通过\n",
+	"config.no_files":             "未找到配置文件，使用默认配置\n",
+	"config.invalid_server":       "必须是 http:// 或 https:// 开头的地址，当前为 %q",
+	"config.invalid_files":        "%d 个配置文件校验未通过",
+
+	// MySQL 备份恢复
+	"mysql.instance_not_found":       "未找到 MySQL 实例 %s：不存在同名的 StatefulSet 或 Pod",
+	"mysql.no_ready_pod":             "MySQL 实例 %s 没有处于 Running 且 Ready 的 Pod",
+	"mysql.list_db_failed":           "查询数据库列表失败: %w",
+	"mysql.dump_failed":              "备份数据库 %s 失败: %v",
+	"mysql.dump_exists":              "%s 已存在",
+	"mysql.not_dump":                 "%s 不是 devops-tool 生成的 MySQL 备份: %v",
+	"mysql.restore_failed":           "将 %s 恢复到 %s 失败: %w",
+	"instance.restored":              "已将 %s 恢复到 %s\n",
+	"instance.flag_required":         "必须指定 --%s",
+	"mysql.database_required":        "未指定 --file 时必须通过 --database 选择要恢复的数据库",
+	"mysql.src_mismatch":             "%s 备份自 %s，而不是 %s",
+	"mysql.no_backup":                "在 %[3]s 下未找到 %[2]s 数据库 %[1]s 的备份",
+	"mysql.invalid_samples":          "--sample-tables 至少为 1，当前为 %d",
+	"mysql.invalid_password_env":     "无效的 --password-env %q，必须是环境变量名",
+	"mysql.verify_not_ready":         "临时 MySQL 实例未就绪: %v",
+	"mysql.verify_pod_created":       "已创建临时 MySQL Pod %s/%s\n",
+	"mysql.verify_pod_exited":        "临时 MySQL Pod 已退出，状态为 %s",
+	"mysql.verify_kept":              "已保留临时 MySQL Pod %s/%s，排查后请手动删除\n",
+	"mysql.verify_cleanup_failed":    "删除 %s 失败: %v\n",
+	"mysql.verify_no_tables":         "恢复后数据库 %s 中没有表",
+	"mysql.verify_unexpected_output": "输出异常: %[3]d 张表只得到 %[1]d 个行数和 %[2]d 个校验和",
+	"mysql.verify_checksum_failed":   "表 %s 的 CHECKSUM TABLE 失败",
+	"mysql.verify_record_failed":     "验证结果写入备份目录索引失败: %v\n",
+	"mysql.verify_failed":            "验证 %s 失败: %w",
+	"mysql.verify_passed":            "验证 %s 通过: 已恢复 %d 张表，耗时 %s\n",
+
+	// TiDB 备份恢复
+	"tidb.service_not_found": "未找到 Service %s/%s，请确认 TiDB 集群名称",
+	"tidb.invalid_tool":      "无效的工具 %q，只能是 dumpling 或 br",
+	"tidb.invalid_secret":    "无效的 --password-secret %q，格式应为 <secret>/<key>",
+	"tidb.pvc_required":      "%s 需要通过 --pvc 指定读写备份的 PVC",
+	"tidb.job_create_failed": "创建 Job %s 失败: %w",
+	"tidb.job_created":       "已创建 Job %s/%s，等待执行完成\n",
+	"tidb.job_wait_failed":   "等待 Job %s 失败: %w",
+	"tidb.job_failed":        "Job %s/%s 执行失败，最后的日志:\n%s",
+	"tidb.meta_missing":      "Job %s/%s 已结束，但没有输出备份元数据",
+	"tidb.record_failed":     "记录备份 %s 失败: %w",
+	"tidb.no_backup":         "%s 没有与 %q 匹配的备份记录",
+	"tidb.catalog_not_dir":   "--catalog %s 不是目录，应为备份 PVC 在本机的挂载目录",
+
+	// 备份目录索引
+	"catalog.parse_failed":      "解析备份目录索引 %s 失败: %v",
+	"catalog.locked":            "备份目录索引被其他进程锁定，如果没有备份在运行，请删除 %s",
+	"catalog.record_failed":     "更新备份目录索引失败: %v",
+	"catalog.remove_failed":     "删除 %s 失败: %v",
+	"catalog.policy_negative":   "--keep-daily、--keep-weekly 和 --keep-monthly 不能为负数",
+	"catalog.policy_empty":      "--keep-daily、--keep-weekly 和 --keep-monthly 至少有一个大于 0",
+	"catalog.checksum_mismatch": "%s 已损坏：索引中的校验和为 %s，文件校验和为 %s",
+	"catalog.pruned":            "已清理 %d 个备份\n",
+	"catalog.would_prune":       "将清理 %d 个备份\n",
+	"catalog.entry_not_found":   "备份目录 %[2]s 的索引中没有 %[1]s",
+	"catalog.outside_root":      "拒绝删除 %s：不在备份根目录 %s 下",
+
+	// 表格列名
+	"column.NAME":              "名称",
+	"column.PROVISIONER":       "供应者",
+	"column.RECLAIM POLICY":    "回收策略",
+	"column.NAMESPACE BOUND":   "绑定命名空间",
+	"column.CAPACITY":          "容量",
+	"column.ACCESS MODES":      "访问模式",
+	"column.STATUS":            "状态",
+	"column.CLAIM":             "绑定 PVC",
+	"column.STORAGECLASS":      "存储类",
+	"column.TYPE":              "类型",
+	"column.LOCATION":          "位置",
+	"column.AGE":               "创建时长",
+	"column.NODE_ISEXIST":      "节点存在",
+	"column.BONDPVCISEXIST":    "PVC 存在",
+	"column.PVCINUSE":          "PVC 使用中",
+	"column.KIND":              "资源类型",
+	"column.REASON":            "原因",
+	"column.CHANGE":            "变更",
+	"column.FIELD":             "字段",
+	"column.OLD":               "旧值",
+	"column.NEW":               "新值",
+	"column.RESOURCE":          "资源",
+	"column.CHECK":             "检查项",
+	"column.DETAIL":            "详情",
+	"column.TIME":              "时间",
+	"column.INSTANCE":          "实例",
+	"column.DATABASE":          "数据库",
+	"column.SIZE":              "大小",
+	"column.FILE":              "文件",
+	"column.TOOL":              "工具",
+	"column.TSO":               "TSO",
+	"column.PATH":              "路径",
+	"column.ENGINE":            "引擎",
+	"column.CHECKSUM":          "校验和",
+	"column.CURRENT":           "当前",
+	"column.SIGN":              "集群标识",
+	"column.KUBECONFIG":        "KUBECONFIG",
+	"column.CONTEXT":           "CONTEXT",
+	"column.SERVER":            "API 地址",
+	"column.LABELS":            "标签",
+	"column.DESCRIPTION":       "描述",
+	"column.TABLE":             "表",
+	"column.ROWS":              "行数",
+	"column.VERIFIED":          "恢复验证",
+	"column.STORAGECLASSES":    "存储类",
+	"column.QUOTA":             "配额（已用/上限）",
+	"column.PVCS":              "PVC 数",
+	"column.REQUESTED STORAGE": "申请容量",
+	"column.DEPLOYMENTS":       "Deployment",
+	"column.STATEFULSETS":      "StatefulSet",
+	"column.DAEMONSETS":        "DaemonSet",
+	"column.CRONJOBS":          "CronJob",
+	"column.JOBS":              "Job",
+	"column.PODS":              "Pod 数",
+	"column.ROLES":             "角色",
+	"column.KUBELET":           "Kubelet 版本",
+	"column.RUNTIME":           "容器运行时",
+	"column.OS IMAGE":          "操作系统",
+	"column.CPU REQUESTS":      "CPU 请求/可分配",
+	"column.MEMORY REQUESTS":   "内存请求/可分配",
+	"column.PRESSURE":          "压力状态",
+	"column.TAINTS":            "污点",
+	"column.LOCAL PVS":         "Local PV（容量）",
+	"column.READY":             "就绪/期望",
+	"column.NAMESPACE":         "命名空间",
+	"column.IMAGES":            "镜像",
+	"column.REQUESTS":          "请求",
+	"column.LIMITS":            "限制",
+	"column.NODES":             "节点分布",
+	"column.IMAGE":             "镜像",
+	"column.REGISTRY":          "仓库",
+	"column.TEMPLATES":         "模板引用",
+	"column.NAMESPACES":        "命名空间",
+	"column.PULL POLICY":       "拉取策略",
+	"column.DIGEST":            "Digest",
+	"column.ISSUES":            "问题",
+	"column.WORKLOAD":          "工作负载",
+	"column.CONTAINER":         "容器",
+	"column.CPU REQ/LIMIT":     "CPU 请求/限制",
+	"column.CPU AVG/P95":       "CPU 平均/P95",
+	"column.REC CPU REQ/LIMIT": "推荐 CPU 请求/限制",
+	"column.MEM REQ/LIMIT":     "内存请求/限制",
+	"column.MEM AVG/PEAK":      "内存平均/峰值",
+	"column.REC MEM REQ/LIMIT": "推荐内存请求/限制",
+
+	// 命令帮助
+	"help.root":                 "devops-tool 运维命令行工具",
+	"help.cluster":              "集群存储相关命令",
+	"help.cluster.get-sc":       "查看 StorageClass 资源",
+	"help.cluster.get-pv":       "查看 PV 资源",
+	"help.cluster.get-ns":       "查看命名空间绑定的 StorageClass、配额用量、PVC 和工作负载数量",
+	"help.cluster.get-node":     "查看节点版本、可分配与已请求资源、压力状态、污点和 local PV",
+	"help.cluster.get-workload": "查看 Deployment、StatefulSet、DaemonSet、CronJob 和 Job 的镜像、资源、PVC 和节点分布",
+	"help.cluster.get-images":   "汇总 Pod 和工作负载模板中的镜像，并标记 tag 和仓库策略问题",
+	"help.cluster.rightsizing":  "比较容器 requests/limits 与 metrics-server 用量并给出推荐值",
+	"long.cluster.rightsizing": `比较容器 requests/limits 与 metrics.k8s.io 中的用量，按工作负载中的容器给出推荐值。
+
+未指定 --window 时只读取一次用量；指定 --window 时在窗口内每隔 --interval 采样一次。
+CPU 请求按 P95、内存请求按峰值推荐，并加上 --headroom 预留。内存 limit 预留两倍 headroom，
+CPU limit 只在当前已设置时推荐为请求的两倍。
+
+采样在结束后按快照中的 Pod 匹配。窗口内被删除的 Pod（例如滚动更新替换掉的 Pod）的用量
+不计入推荐值，其数量会以警告输出。`,
+	"long.cluster.get-images": `汇总 Pod 和工作负载模板中的容器及 init 容器镜像，包括使用次数、命名空间、
+拉取策略以及 Pod status 中的镜像 digest。
+
+以下镜像会被标记：使用 latest tag 且未固定 digest（latest-tag），仓库不在
+--allowed-registry 中（registry-not-allowed），只出现在 Deployment、StatefulSet
+或 DaemonSet 模板中而没有运行中的 Pod（not-running）。
+Docker Hub 官方镜像按 docker.io/library/<name> 匹配，例如 redis 可以用
+--allowed-registry docker.io/library 放行。`,
+	"help.cluster.clean-storage":  "清理未使用的 StorageClass 和 PV 资源",
+	"help.cluster.clean-plan":     "查看 clean-storage 将会删除的 StorageClass 和 PV",
+	"help.cluster.storage-diff":   "比较两个快照之间，或快照与当前集群之间的 PV 和 StorageClass 差异",
+	"help.cluster.storage-report": "以 Excel、HTML 或 Markdown 格式导出 StorageClass、PV、PVC 和孤儿资源报表",
+	"help.cluster.snapshot":       "快照相关命令",
+	"help.cluster.snapshot.save":  "保存存储相关资源，供 --from-snapshot 离线分析",
+	"help.exporter":               "为 Prometheus 暴露存储资源指标",
+	"help.controller":             "通过选主按计划执行存储清理",
+	"long.controller": `通过选主按计划执行存储清理。
+
+内置策略按 --schedule 执行 clean-storage，未指定 --dry-run=false 时只记录将要删除的资源，
+--reason 限定清理的原因。StorageCleanupPolicy 每隔 --policy-sync-period 检查一次，
+使用策略自身的 dryRun 和 reasons。`,
+	"help.policy":         "StorageCleanupPolicy 相关命令",
+	"help.policy.crd":     "输出 StorageCleanupPolicy 的 CRD 清单",
+	"help.install":        "在集群中部署 devops-tool",
+	"help.install.render": "输出 ServiceAccount、最小权限 RBAC、工作负载和备份 PVC 清单",
+	"long.install.render": `输出在集群内运行 devops-tool 所需的清单，可直接通过 kubectl apply -f - 部署。
+
+ClusterRole 只包含所选模式需要的权限。controller 模式还需要通过
+"devops-tool policy crd" 安装 StorageCleanupPolicy CRD。`,
+	"help.doctor":            "检查集群连通性、服务端版本、RBAC 权限和备份目录",
+	"help.config":            "查看和修改 devops-tool 配置文件",
+	"help.config.view":       "输出叠加配置文件、profile 和环境变量后的生效配置",
+	"help.config.set":        "修改配置文件中以 . 分隔的 key，例如 cleanup.concurrency 或 profiles.prod.context",
+	"help.config.clusters":   "列出集群登记表，默认的集群标识以 * 标记",
+	"help.config.validate":   "检查配置文件中的未知字段和非法取值",
+	"help.mysql":             "MySQL 逻辑备份与恢复命令",
+	"help.mysql.backup":      "在实例 Pod 中执行 mysqldump，并将 gzip 压缩的备份写入备份根目录",
+	"help.mysql.list-backup": "列出备份根目录下的备份及其大小和时间",
+	"help.mysql.restore":     "将备份导入 MySQL 实例",
+	"long.mysql.restore": `将备份导入 MySQL 实例。
+
+未指定 --file 时使用源实例中 --database 最新的备份。
+同时指定 --file 和源实例时，备份文件必须来自该实例。`,
+	"help.mysql.verify-backup": "将备份恢复到临时 MySQL 实例并执行检查",
+	"long.mysql.verify-backup": `将备份恢复到临时 MySQL 实例并执行检查。
+
+在临时命名空间中创建 Pod（命名空间不存在时创建，结束后删除），恢复备份，统计表数量，
+并对部分表统计行数和执行 CHECKSUM TABLE。除非指定 --keep，结束后删除 Pod。
+验证结果记录到备份目录索引中该备份的记录上，索引中没有该备份时只给出警告。
+
+指定 --docker-container 时恢复到本地 MySQL 容器，不需要访问集群。`,
+	"help.tidb":        "基于 BR 和 Dumpling Job 的 TiDB 备份与恢复命令",
+	"help.tidb.backup": "运行 BR 或 Dumpling Job 备份集群并记录备份元数据",
+	"long.tidb.backup": `在 TidbCluster 所在命名空间运行 BR 或 Dumpling Job，并将备份元数据
+（数据库、TSO、大小、路径）记录在与 Job 同名的 ConfigMap 中。
+
+Dumpling 写入挂载在 --backup-root 的 --pvc。BR 写入 --storage，未指定时写入
+local://<backup-root>/...，此时该存储必须同时挂载到 TiKV。
+
+指定 --catalog 时，备份还会记录到该目录下的 catalog.json，供 backup prune 过期清理，
+该目录必须是备份 PVC 在本机的挂载目录。`,
+	"help.tidb.list-backup": "列出 TiDB 集群的备份记录",
+	"help.tidb.restore":     "将备份恢复到 TiDB 集群，支持跨命名空间",
+	"long.tidb.restore": `将备份恢复到 TiDB 集群，支持跨命名空间。
+
+恢复 Job 运行在 --src-namespace 以便挂载备份 PVC，并通过 Service 域名访问目标集群。
+未指定 --file 时恢复源集群最新的备份。BR 备份使用 br 恢复，Dumpling 备份使用 tidb-lightning 恢复。`,
+	"help.backup":       "管理 MySQL 和 TiDB 备份的目录索引",
+	"help.backup.list":  "列出目录索引中记录的备份",
+	"help.backup.prune": "按祖父-父-子（GFS）保留策略删除过期备份",
+	"long.backup.prune": `按祖父-父-子（GFS）保留策略删除过期备份。
+
+每个实例和数据库分别保留最近 --keep-daily 天、--keep-weekly 个 ISO 周和 --keep-monthly 个月中
+每个周期最新的一个成功备份。失败的备份总是被清理。对象存储上的 BR 备份只从索引中移除。`,
+	"help.completion": "生成指定 shell 的自动补全脚本",
+	"long.completion": `生成 devops-tool 的自动补全脚本。
+
+  bash:       source <(devops-tool completion bash)
+  zsh:        devops-tool completion zsh > "${fpath[1]}/_devops-tool"
+  fish:       devops-tool completion fish > ~/.config/fish/completions/devops-tool.fish
+  powershell: devops-tool completion powershell | Out-String | Invoke-Expression
+
+StorageClass、PV、命名空间和 context 名称从当前集群实时补全。`,
+
+	// flag 说明，flag.<命令路径>.<flag> 优先于 flag.<flag>
+	"flag.lang":                                  "输出语言: zh-CN 或 en（默认读取 LANG）",
+	"flag.qps":                                   "客户端 API 请求 QPS 限制",
+	"flag.burst":                                 "客户端 API 请求突发上限",
+	"flag.skip-preflight":                        "跳过 RBAC 权限预检",
+	"flag.file":                                  "输出文件路径",
+	"flag.from-snapshot":                         "从快照文件或目录加载资源，而不是访问集群",
+	"flag.concurrency":                           "并发删除的 worker 数量",
+	"flag.output":                                "输出格式: table|json",
+	"flag.format":                                "报表格式: xlsx|html|markdown",
+	"flag.template":                              "html/markdown 格式使用的自定义 Go 模板文件",
+	"flag.dir":                                   "输出目录（默认 snapshot-<时间戳>）",
+	"flag.listen":                                "暴露 /metrics 的监听地址",
+	"flag.interval":                              "存储数据刷新间隔",
+	"flag.controller.schedule":                   "内置清理策略的 cron 表达式，为空时只执行 StorageCleanupPolicy",
+	"flag.controller.dry-run":                    "内置策略只记录将要删除的资源，指定 --dry-run=false 才会删除",
+	"flag.controller.reason":                     "内置策略只清理这些原因的资源，可重复指定；NodeMissing 的 local PV 只有在指定时才会清理",
+	"flag.controller.policy-sync-period":         "检查 StorageCleanupPolicy 是否到期的间隔，0 表示不处理",
+	"flag.controller.namespace":                  "存放选主 Lease 以及读取暂停注解的命名空间",
+	"flag.lease-name":                            "选主 Lease 名称",
+	"flag.identity":                              "参与选主的实例标识（默认主机名）",
+	"flag.health-addr":                           "提供 /healthz 和 /readyz 的监听地址",
+	"flag.install.render.mode":                   "部署模式: cronjob、controller 或 exporter",
+	"flag.install.render.namespace":              "部署的命名空间",
+	"flag.name":                                  "生成资源的名称",
+	"flag.image":                                 "容器镜像",
+	"flag.install.render.schedule":               "cronjob 模式的 cron 表达式",
+	"flag.backup-size":                           "备份 PVC 容量",
+	"flag.backup-storage-class":                  "备份 PVC 使用的 StorageClass（默认使用集群默认值）",
+	"flag.doctor.mode":                           "检查的权限: cronjob (clean-storage)、controller 或 exporter (只读)",
+	"flag.doctor.namespace":                      "检查 controller Lease 权限的命名空间",
+	"flag.doctor.backup-dir":                     "检查是否可写的备份目录",
+	"flag.backup-dir":                            "删除前备份资源 YAML 的根目录",
+	"flag.config":                                "配置文件路径（默认叠加 /etc/devops-tool/config.yaml 和 ~/.devops-tool.yaml）",
+	"flag.profile":                               "使用配置文件中的 profile",
+	"flag.kubeconfig":                            "kubeconfig 路径（默认 KUBECONFIG 或 ~/.kube/config）",
+	"flag.cluster-sign":                          "配置文件 clusters 中登记的集群标识，指定后替代 --kubeconfig 和 --context",
+	"flag.context":                               "使用的 kubeconfig context",
+	"flag.dry-run":                               "只记录将要删除的资源，不做备份和删除",
+	"flag.reason":                                "只清理这些原因的资源，可重复指定；NodeMissing 的 local PV 只有在指定时才会清理",
+	"flag.sc":                                    "只列出该 StorageClass 的 PV",
+	"flag.backup-root":                           "备份文件根目录",
+	"flag.container":                             "运行 mysql 的容器（默认 Pod 的第一个容器）",
+	"flag.user":                                  "MySQL 用户",
+	"flag.password-env":                          "容器中保存密码的环境变量",
+	"flag.mysql.backup.namespace":                "实例所在命名空间",
+	"flag.mysql.backup.name":                     "实例的 StatefulSet 或 Pod 名称",
+	"flag.mysql.backup.database":                 "要备份的数据库，all 表示全部非系统库",
+	"flag.mysql.list-backup.namespace":           "实例所在命名空间",
+	"flag.mysql.list-backup.name":                "只列出该实例的备份",
+	"flag.src-namespace":                         "备份来源实例所在命名空间",
+	"flag.src-name":                              "备份来源实例名称",
+	"flag.dest-namespace":                        "恢复目标实例所在命名空间（默认 --src-namespace）",
+	"flag.dest-name":                             "恢复目标实例名称",
+	"flag.mysql.restore.file":                    "要恢复的备份文件",
+	"flag.mysql.restore.database":                "未指定 --file 时恢复该数据库最新的备份",
+	"flag.mysql.verify-backup.file":              "要验证的备份文件",
+	"flag.mysql.verify-backup.scratch-namespace": "临时实例所在命名空间",
+	"flag.mysql.verify-backup.image":             "临时实例的镜像",
+	"flag.mysql.verify-backup.sample-tables":     "统计行数并执行 CHECKSUM TABLE 的表数量",
+	"flag.mysql.verify-backup.timeout":           "等待临时实例启动的超时时间",
+	"flag.mysql.verify-backup.keep":              "保留临时实例便于排查",
+	"flag.mysql.verify-backup.docker-container":  "通过 docker exec 在该本地 MySQL 容器中验证，不使用集群",
+	"flag.tidb.backup-root":                      "备份 PVC 在 Job 中的挂载路径",
+	"flag.pvc":                                   "挂载到 --backup-root 的备份 PVC，位于 Job 所在命名空间",
+	"flag.tidb.image":                            "Job 镜像（默认与 TidbCluster 版本一致的 pingcap/<tool>）",
+	"flag.tidb.user":                             "Dumpling 和 tidb-lightning 使用的 TiDB 用户",
+	"flag.password-secret":                       "保存 TiDB 密码的 <secret>/<key>，位于 Job 所在命名空间",
+	"flag.timeout":                               "等待 Job 完成的超时时间",
+	"flag.tidb.backup.namespace":                 "TidbCluster 所在命名空间",
+	"flag.tidb.backup.name":                      "TidbCluster 名称",
+	"flag.tool":                                  "备份工具: dumpling 或 br",
+	"flag.tidb.backup.database":                  "要备份的数据库，all 表示全部非系统库",
+	"flag.tidb.backup.catalog":                   "备份 PVC 在本机的挂载目录，备份将记录到其中的 catalog.json",
+	"flag.storage":                               "BR 存储地址，例如 s3://bucket/prefix",
+	"flag.tidb.list-backup.namespace":            "TidbCluster 所在命名空间",
+	"flag.tidb.list-backup.name":                 "只列出该 TidbCluster 的备份",
+	"flag.tidb.restore.src-namespace":            "被备份的 TidbCluster 以及备份 PVC 所在命名空间",
+	"flag.tidb.restore.src-name":                 "被备份的 TidbCluster 名称",
+	"flag.tidb.restore.dest-namespace":           "恢复目标 TidbCluster 所在命名空间（默认 --src-namespace）",
+	"flag.tidb.restore.dest-name":                "恢复目标 TidbCluster 名称",
+	"flag.tidb.restore.file":                     "list-backup 显示的备份路径或 BR 存储地址（默认最新的备份）",
+	"flag.backup.backup-root":                    "保存 catalog.json 的备份根目录",
+	"flag.keep-daily":                            "按天保留最新备份的天数",
+	"flag.keep-weekly":                           "按周保留最新备份的 ISO 周数",
+	"flag.keep-monthly":                          "按月保留最新备份的月数",
+	"flag.backup.prune.dry-run":                  "只列出将要删除的备份",
+	"flag.cluster.get-pv.namespace":              "只列出绑定到该命名空间 PVC 的 PV",
+	"flag.cluster.get-workload.namespace":        "只列出该命名空间中的工作负载",
+	"flag.cluster.get-workload.kind":             "只列出这些类型：Deployment、StatefulSet、DaemonSet、CronJob、Job",
+	"flag.cluster.get-images.namespace":          "只统计该命名空间中的 Pod 和工作负载",
+	"flag.allowed-registry":                      "允许的镜像仓库或仓库路径前缀，其他仓库的镜像会被标记",
+	"flag.issues-only":                           "只列出存在问题的镜像",
+	"flag.cluster.rightsizing.namespace":         "只分析该命名空间中的工作负载",
+	"flag.cluster.rightsizing.window":            "在该时间窗口内多次采样，而不是只读取一次",
+	"flag.cluster.rightsizing.interval":          "--window 内的采样间隔",
+	"flag.cluster.rightsizing.headroom":          "推荐值在观测用量之上预留的比例",
+}
@@ -0,0 +1,148 @@
+package i18n
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+	"strings"
+)
+
+// Lang 输出语言
+type Lang string
+
+const (
+	ZhCN Lang = "zh-CN"
+	En   Lang = "en"
+)
+
+var catalogs = map[Lang]map[string]string{
+	ZhCN: zhCN,
+	En:   en,
+}
+
+var current = ZhCN
+
+// ParseLang 解析 --lang 或 LANG 的取值，zh、zh_CN.UTF-8 等归为 zh-CN，en_US.UTF-8 等归为 en
+func ParseLang(s string) (Lang, error) {
+	s = strings.ToLower(strings.SplitN(s, ".", 2)[0])
+	switch {
+	case strings.HasPrefix(s, "zh"):
+		return ZhCN, nil
+	case strings.HasPrefix(s, "en"):
+		return En, nil
+	}
+	return "", fmt.Errorf("unsupported language %q, must be zh-CN or en", s)
+}
+
+// SetLang 切换当前语言
+func SetLang(lang Lang) {
+	current = lang
+}
+
+// Current 返回当前语言
+func Current() Lang {
+	return current
+}
+
+// Init 按 lang（来自 --lang 或配置文件）、LC_ALL、LANG 的顺序确定语言，都未设置或无法识别时使用 zh-CN。
+// 需要在 cobra 解析参数之前调用，这样帮助信息也能使用对应语言
+func Init(lang string) error {
+	if lang != "" {
+		l, err := ParseLang(lang)
+		if err != nil {
+			return err
+		}
+		SetLang(l)
+		return nil
+	}
+	for _, env := range []string{"LC_ALL", "LANG"} {
+		if l, err := ParseLang(os.Getenv(env)); err == nil {
+			SetLang(l)
+			return nil
+		}
+	}
+	SetLang(ZhCN)
+	return nil
+}
+
+func lookup(id string) (string, bool) {
+	if msg, ok := catalogs[current][id]; ok {
+		return msg, true
+	}
+	msg, ok := en[id]
+	return msg, ok
+}
+
+// T 返回消息 id 在当前语言下的文本，args 非空时按格式化字符串处理，
+// 当前语言缺少该消息时回退到 en，都没有时返回 id
+func T(id string, args ...interface{}) string {
+	msg, ok := lookup(id)
+	if !ok {
+		msg = id
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Errorf 以消息 id 构造错误，消息中可以使用 %w
+func Errorf(id string, args ...interface{}) error {
+	msg, ok := lookup(id)
+	if !ok {
+		msg = id
+	}
+	return fmt.Errorf(msg, args...)
+}
+
+// Header 翻译表格列名并以 tab 连接，列名本身即 en 文本
+func Header(columns ...string) string {
+	translated := make([]string, len(columns))
+	for i, column := range columns {
+		translated[i] = column
+		if msg, ok := catalogs[current]["column."+column]; ok {
+			translated[i] = msg
+		}
+	}
+	return strings.Join(translated, "\t")
+}
+
+// LocalizeCommands 替换命令树的帮助信息。代码中的 Short、Long 和 flag 说明即 en 文本，
+// 其他语言分别从 help.<路径>、long.<路径>、flag.<路径>.<flag> 或 flag.<flag> 读取，
+// 路径为去掉根命令后以 . 连接的命令名，例如 help.cluster.get-sc
+func LocalizeCommands(root *cobra.Command) {
+	if current == En {
+		return
+	}
+	var walk func(cmd *cobra.Command, path string)
+	walk = func(cmd *cobra.Command, path string) {
+		key := path
+		if key == "" {
+			key = "root"
+		}
+		if msg, ok := catalogs[current]["help."+key]; ok {
+			cmd.Short = msg
+		}
+		if msg, ok := catalogs[current]["long."+key]; ok {
+			cmd.Long = msg
+		}
+		localizeFlag := func(f *pflag.Flag) {
+			if msg, ok := catalogs[current]["flag."+key+"."+f.Name]; ok {
+				f.Usage = msg
+			} else if msg, ok := catalogs[current]["flag."+f.Name]; ok {
+				f.Usage = msg
+			}
+		}
+		cmd.Flags().VisitAll(localizeFlag)
+		cmd.PersistentFlags().VisitAll(localizeFlag)
+		for _, child := range cmd.Commands() {
+			childPath := child.Name()
+			if path != "" {
+				childPath = path + "." + child.Name()
+			}
+			walk(child, childPath)
+		}
+	}
+	walk(root, "")
+}
@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var verbPattern = regexp.MustCompile(`%(\[\d+\])?[-+# 0-9.]*[a-zA-Z%]`)
+
+// verbs 返回格式化动词，带显式下标的按下标排序，以便比较语序不同的翻译
+func verbs(msg string) []string {
+	found := verbPattern.FindAllString(msg, -1)
+	for i, v := range found {
+		if !strings.Contains(v, "[") {
+			found[i] = "[" + string(rune('1'+i)) + "]" + v[1:]
+		} else {
+			found[i] = v[1:]
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+func TestCatalogsMatch(t *testing.T) {
+	for id, msg := range en {
+		zh, ok := zhCN[id]
+		if !ok {
+			t.Errorf("zh-CN catalog missing %q", id)
+			continue
+		}
+		if got, want := strings.Join(verbs(zh), " "), strings.Join(verbs(msg), " "); got != want {
+			t.Errorf("%q: zh-CN verbs %s, en verbs %s", id, got, want)
+		}
+	}
+	for id := range zhCN {
+		_, ok := en[id]
+		// 命令帮助和列名的英文在代码中
+		for _, prefix := range []string{"help.", "long.", "flag.", "column."} {
+			if strings.HasPrefix(id, prefix) {
+				ok = true
+			}
+		}
+		if !ok {
+			t.Errorf("en catalog missing %q", id)
+		}
+	}
+}
+
+func TestParseLang(t *testing.T) {
+	for in, want := range map[string]Lang{"zh_CN.UTF-8": ZhCN, "zh-CN": ZhCN, "en_US.UTF-8": En, "en": En} {
+		if got, err := ParseLang(in); err != nil || got != want {
+			t.Errorf("ParseLang(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseLang("C"); err == nil {
+		t.Error("ParseLang(\"C\") should fail")
+	}
+}
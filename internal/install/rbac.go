@@ -0,0 +1,82 @@
+package install
+
+import (
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
+	"devops_tools/internal/policy"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// Mode 工具在集群内的运行方式
+type Mode string
+
+const (
+	ModeCronJob    Mode = "cronjob"
+	ModeController Mode = "controller"
+	ModeExporter   Mode = "exporter"
+)
+
+// ParseMode 校验 --mode 参数
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ModeCronJob, ModeController, ModeExporter:
+		return m, nil
+	}
+	return "", apperr.ValidationError(i18n.Errorf("install.unsupported_mode", s))
+}
+
+// snapshotRules cluster.LoadSnapshot 需要 list 的全部资源，三种模式都要加载快照
+var snapshotRules = []rbacv1.PolicyRule{
+	{APIGroups: []string{""}, Resources: []string{"namespaces", "nodes", "persistentvolumes", "persistentvolumeclaims", "pods", "resourcequotas"}, Verbs: []string{"list"}},
+	{APIGroups: []string{"storage.k8s.io"}, Resources: []string{"storageclasses"}, Verbs: []string{"list"}},
+	{APIGroups: []string{"apps"}, Resources: []string{"deployments", "daemonsets", "statefulsets"}, Verbs: []string{"list"}},
+	{APIGroups: []string{"batch"}, Resources: []string{"cronjobs", "jobs"}, Verbs: []string{"list"}},
+}
+
+// deleteRules clean-storage 删除 PV 和 StorageClass
+var deleteRules = []rbacv1.PolicyRule{
+	{APIGroups: []string{""}, Resources: []string{"persistentvolumes"}, Verbs: []string{"delete"}},
+	{APIGroups: []string{"storage.k8s.io"}, Resources: []string{"storageclasses"}, Verbs: []string{"delete"}},
+}
+
+// controllerRules 控制器额外需要：读取暂停注解、记录事件、处理 StorageCleanupPolicy
+var controllerRules = []rbacv1.PolicyRule{
+	{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get"}},
+	{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create", "patch"}},
+	{APIGroups: []string{policy.Group}, Resources: []string{policy.GroupVersionResource.Resource}, Verbs: []string{"list"}},
+	{APIGroups: []string{policy.Group}, Resources: []string{policy.GroupVersionResource.Resource + "/status"}, Verbs: []string{"update"}},
+}
+
+// leaseRules 控制器选主使用的 Lease，只授权在安装命名空间内
+var leaseRules = []rbacv1.PolicyRule{
+	{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"get", "create", "update"}},
+}
+
+// SnapshotRules 只读命令（get-sc、get-pv、clean-plan 等）需要的权限
+func SnapshotRules() []rbacv1.PolicyRule {
+	return append([]rbacv1.PolicyRule{}, snapshotRules...)
+}
+
+// CleanStorageRules clean-storage 需要的权限
+func CleanStorageRules() []rbacv1.PolicyRule {
+	return append(SnapshotRules(), deleteRules...)
+}
+
+// ClusterRules 返回 mode 需要的集群级权限
+func ClusterRules(mode Mode) []rbacv1.PolicyRule {
+	switch mode {
+	case ModeCronJob:
+		return CleanStorageRules()
+	case ModeController:
+		return append(CleanStorageRules(), controllerRules...)
+	}
+	return SnapshotRules()
+}
+
+// NamespaceRules 返回 mode 需要的命名空间级权限，没有时返回 nil
+func NamespaceRules(mode Mode) []rbacv1.PolicyRule {
+	if mode == ModeController {
+		return leaseRules
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package install
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	"testing"
+)
+
+func allows(rules []rbacv1.PolicyRule, group, resource, verb string) bool {
+	for _, r := range rules {
+		if contains(r.APIGroups, group) && contains(r.Resources, resource) && contains(r.Verbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestClusterRules(t *testing.T) {
+	cases := []struct {
+		mode                             Mode
+		deletePV, deleteSC, updatePolicy bool
+	}{
+		{ModeCronJob, true, true, false},
+		{ModeController, true, true, true},
+		{ModeExporter, false, false, false},
+	}
+	for _, tc := range cases {
+		rules := ClusterRules(tc.mode)
+		if !allows(rules, "", "persistentvolumes", "list") {
+			t.Errorf("%s: missing list persistentvolumes", tc.mode)
+		}
+		if got := allows(rules, "", "persistentvolumes", "delete"); got != tc.deletePV {
+			t.Errorf("%s: delete persistentvolumes = %v, want %v", tc.mode, got, tc.deletePV)
+		}
+		if got := allows(rules, "storage.k8s.io", "storageclasses", "delete"); got != tc.deleteSC {
+			t.Errorf("%s: delete storageclasses = %v, want %v", tc.mode, got, tc.deleteSC)
+		}
+		if got := allows(rules, "devops-tool.io", "storagecleanuppolicies/status", "update"); got != tc.updatePolicy {
+			t.Errorf("%s: update policy status = %v, want %v", tc.mode, got, tc.updatePolicy)
+		}
+	}
+}
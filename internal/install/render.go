@@ -0,0 +1,202 @@
+package install
+
+import (
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
+	"io"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+// backupMountPath 与 cluster 包中的备份和日志目录一致
+const backupMountPath = "/data/storage-clean"
+
+// Options 生成清单的参数
+type Options struct {
+	Mode      Mode
+	Namespace string
+	Name      string
+	Image     string
+	// Schedule cronjob 模式的 cron 表达式
+	Schedule string
+	// BackupSize 备份 PVC 的容量，exporter 模式不创建 PVC
+	BackupSize string
+	// BackupStorageClass 备份 PVC 使用的 StorageClass，为空时使用集群默认值
+	BackupStorageClass string
+}
+
+// Render 按 mode 生成 ServiceAccount、RBAC、工作负载以及备份 PVC，以 --- 分隔写入 w
+func Render(opts Options, w io.Writer) error {
+	objects, err := Objects(opts)
+	if err != nil {
+		return err
+	}
+	for i, obj := range objects {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Objects 返回 mode 需要的全部对象，顺序即 kubectl apply 的创建顺序
+func Objects(opts Options) ([]interface{}, error) {
+	labels := map[string]string{"app.kubernetes.io/name": opts.Name, "app.kubernetes.io/component": string(opts.Mode)}
+	meta := func(name string, namespaced bool) metaV1.ObjectMeta {
+		m := metaV1.ObjectMeta{Name: name, Labels: labels}
+		if namespaced {
+			m.Namespace = opts.Namespace
+		}
+		return m
+	}
+	subject := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: opts.Name, Namespace: opts.Namespace}}
+
+	objects := []interface{}{
+		&corev1.ServiceAccount{
+			TypeMeta:   metaV1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: meta(opts.Name, true),
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta:   metaV1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: meta(opts.Name, false),
+			Rules:      ClusterRules(opts.Mode),
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metaV1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: meta(opts.Name, false),
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: opts.Name},
+			Subjects:   subject,
+		},
+	}
+	if rules := NamespaceRules(opts.Mode); rules != nil {
+		objects = append(objects,
+			&rbacv1.Role{
+				TypeMeta:   metaV1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+				ObjectMeta: meta(opts.Name, true),
+				Rules:      rules,
+			},
+			&rbacv1.RoleBinding{
+				TypeMeta:   metaV1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+				ObjectMeta: meta(opts.Name, true),
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: opts.Name},
+				Subjects:   subject,
+			},
+		)
+	}
+
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	if opts.Mode != ModeExporter {
+		pvc, err := backupPVC(opts, meta(opts.Name+"-backup", true))
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, pvc)
+		volumes = []corev1.Volume{{
+			Name:         "backup",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name}},
+		}}
+		mounts = []corev1.VolumeMount{{Name: "backup", MountPath: backupMountPath}}
+	}
+
+	container := corev1.Container{Name: opts.Name, Image: opts.Image, VolumeMounts: mounts}
+	podSpec := corev1.PodSpec{ServiceAccountName: opts.Name, Volumes: volumes}
+	switch opts.Mode {
+	case ModeCronJob:
+		container.Args = []string{"cluster", "clean-storage"}
+		podSpec.RestartPolicy = corev1.RestartPolicyNever
+		podSpec.Containers = []corev1.Container{container}
+		objects = append(objects, &batchv1.CronJob{
+			TypeMeta:   metaV1.TypeMeta{APIVersion: batchv1.SchemeGroupVersion.String(), Kind: "CronJob"},
+			ObjectMeta: meta(opts.Name, true),
+			Spec: batchv1.CronJobSpec{
+				Schedule:          opts.Schedule,
+				ConcurrencyPolicy: batchv1.ForbidConcurrent,
+				JobTemplate: batchv1.JobTemplateSpec{
+					Spec: batchv1.JobSpec{
+						Template: corev1.PodTemplateSpec{ObjectMeta: metaV1.ObjectMeta{Labels: labels}, Spec: podSpec},
+					},
+				},
+			},
+		})
+	case ModeController:
+		container.Args = []string{"controller"}
+		container.Env = []corev1.EnvVar{{
+			Name:      "POD_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"}},
+		}}
+		container.Ports = []corev1.ContainerPort{{Name: "health", ContainerPort: 8081}}
+		container.LivenessProbe = httpProbe("/healthz", "health")
+		container.ReadinessProbe = httpProbe("/readyz", "health")
+		podSpec.Containers = []corev1.Container{container}
+		objects = append(objects, deployment(opts, meta(opts.Name, true), labels, nil, podSpec))
+	case ModeExporter:
+		container.Args = []string{"exporter"}
+		container.Ports = []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9100}}
+		container.LivenessProbe = httpProbe("/healthz", "metrics")
+		podSpec.Containers = []corev1.Container{container}
+		annotations := map[string]string{"prometheus.io/scrape": "true", "prometheus.io/port": "9100"}
+		objects = append(objects, deployment(opts, meta(opts.Name, true), labels, annotations, podSpec))
+	default:
+		return nil, apperr.ValidationError(i18n.Errorf("install.unsupported_mode", opts.Mode))
+	}
+	return objects, nil
+}
+
+func backupPVC(opts Options, meta metaV1.ObjectMeta) (*corev1.PersistentVolumeClaim, error) {
+	size, err := resource.ParseQuantity(opts.BackupSize)
+	if err != nil {
+		return nil, apperr.ValidationError(i18n.Errorf("install.invalid_size", opts.BackupSize, err))
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		TypeMeta:   metaV1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: meta,
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources:   corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: size}},
+		},
+	}
+	if opts.BackupStorageClass != "" {
+		pvc.Spec.StorageClassName = &opts.BackupStorageClass
+	}
+	return pvc, nil
+}
+
+// deployment 单副本 Deployment，备份 PVC 为 RWO，使用 Recreate 避免新旧 Pod 同时挂载
+func deployment(opts Options, meta metaV1.ObjectMeta, labels, annotations map[string]string, podSpec corev1.PodSpec) *appsv1.Deployment {
+	replicas := int32(1)
+	return &appsv1.Deployment{
+		TypeMeta:   metaV1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+		ObjectMeta: meta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metaV1.LabelSelector{MatchLabels: labels},
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metaV1.ObjectMeta{Labels: labels, Annotations: annotations},
+				Spec:       podSpec,
+			},
+		},
+	}
+}
+
+func httpProbe(path, port string) *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: path, Port: intstr.FromString(port)}},
+	}
+}
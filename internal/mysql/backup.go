@@ -0,0 +1,246 @@
+package mysql
+
+import (
+	"compress/gzip"
+	"context"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/catalog"
+	"devops_tools/internal/i18n"
+	"errors"
+	"fmt"
+	"io/fs"
+	"k8s.io/client-go/kubernetes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DumpSuffix 备份文件后缀
+const DumpSuffix = ".sql.gz"
+
+// Dump 一个 mysqldump 备份文件。实例、数据库和备份时间保存在 gzip 头中，
+// 文件移动到其他目录后仍然可以识别
+type Dump struct {
+	Path     string
+	Instance Instance
+	Database string
+	Size     int64
+	Time     time.Time
+	// Checksum 仅在刚完成的备份中设置，已有备份的校验和记录在备份目录索引中
+	Checksum string
+}
+
+// BackupOptions backup 命令的参数
+type BackupOptions struct {
+	Conn
+	// Databases 为空或包含 all 时备份除系统库之外的全部数据库
+	Databases []string
+	// Dir 备份根目录，文件写入 <Dir>/<YYYYMMDD>/<namespace>_<name>/<database>/<YYYYMMDDHHMMSS>_<database>.sql.gz
+	Dir string
+}
+
+// DumpPath 返回备份文件路径，不同实例的同名数据库写入不同目录
+func DumpPath(dir string, inst Instance, database string, t time.Time) string {
+	return filepath.Join(dir, t.Format("20060102"), inst.Namespace+"_"+inst.Name, database, t.Format("20060102150405")+"_"+database+DumpSuffix)
+}
+
+// Backup 在实例的 Pod 中执行 mysqldump，每个数据库写入一个 gzip 文件，并将成功和失败的备份记录到 Dir 下的索引，
+// 部分数据库失败时返回已完成的备份和 PartialFailure 类别错误
+func Backup(ctx context.Context, client kubernetes.Interface, inst Instance, opts BackupOptions) ([]Dump, error) {
+	t, err := connect(ctx, client, inst, opts.Conn)
+	if err != nil {
+		return nil, err
+	}
+	databases := opts.Databases
+	if len(databases) == 0 || contains(databases, "all") {
+		if databases, err = t.databases(ctx); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+	var dumps []Dump
+	var entries []catalog.Entry
+	var failed []string
+	for _, db := range databases {
+		path := DumpPath(opts.Dir, inst, db, now)
+		// 同一秒内重复备份时文件已存在，该路径属于已有的备份，不记录失败条目，避免覆盖其索引记录后被清理删除
+		if _, err := os.Stat(path); err == nil {
+			failed = append(failed, i18n.T("mysql.dump_failed", db, i18n.Errorf("mysql.dump_exists", path)))
+			continue
+		}
+		entry := catalog.Entry{Engine: "mysql", Instance: inst.String(), Databases: []string{db}, Path: path, Time: now}
+		dump, err := t.dump(ctx, inst, db, path, now)
+		if err != nil {
+			failed = append(failed, i18n.T("mysql.dump_failed", db, err))
+			entry.Status, entry.Message = catalog.StatusFailed, err.Error()
+		} else {
+			dumps = append(dumps, dump)
+			entry.Status, entry.Size, entry.Checksum = catalog.StatusCompleted, dump.Size, dump.Checksum
+		}
+		entries = append(entries, entry)
+	}
+	if err := catalog.Record(opts.Dir, entries...); err != nil {
+		failed = append(failed, i18n.T("catalog.record_failed", err))
+	}
+	if len(failed) > 0 {
+		return dumps, apperr.PartialFailureError(errors.New(strings.Join(failed, "; ")))
+	}
+	return dumps, nil
+}
+
+// dump 将一个数据库导出到 path，先写临时文件，成功后再重命名
+func (t *target) dump(ctx context.Context, inst Instance, database, path string, now time.Time) (Dump, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Dump{}, err
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return Dump{}, err
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	gz.Name = database
+	gz.Comment = inst.String()
+	gz.ModTime = now
+	script := "mysqldump -u " + quote(t.conn.User) +
+		" --single-transaction --routines --triggers --events --set-gtid-purged=OFF --databases " + quote(database)
+	if err := t.Exec(ctx, script, nil, gz); err != nil {
+		return Dump{}, err
+	}
+	if err := gz.Close(); err != nil {
+		return Dump{}, err
+	}
+	if err := f.Close(); err != nil {
+		return Dump{}, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return Dump{}, err
+	}
+	dump, err := ReadDump(path)
+	if err != nil {
+		return Dump{}, err
+	}
+	dump.Checksum, err = catalog.FileChecksum(path)
+	return dump, err
+}
+
+// ReadDump 从备份文件的 gzip 头读取实例、数据库和备份时间
+func ReadDump(path string) (Dump, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Dump{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return Dump{}, err
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return Dump{}, i18n.Errorf("mysql.not_dump", path, err)
+	}
+	defer gz.Close()
+	inst, ok := ParseInstance(gz.Comment)
+	if !ok || gz.Name == "" {
+		return Dump{}, i18n.Errorf("mysql.not_dump", path, errors.New("missing gzip header"))
+	}
+	return Dump{Path: path, Instance: inst, Database: gz.Name, Size: info.Size(), Time: gz.ModTime}, nil
+}
+
+// ListBackups 列出 dir 下属于 inst 的备份，inst 中为空的字段不参与过滤，按时间倒序。
+// 不是由 Backup 生成的 .sql.gz 文件会被跳过
+func ListBackups(dir string, inst Instance) ([]Dump, error) {
+	var dumps []Dump
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, DumpSuffix) {
+			return nil
+		}
+		dump, err := ReadDump(path)
+		if err != nil {
+			return nil
+		}
+		if (inst.Namespace == "" || dump.Instance.Namespace == inst.Namespace) && (inst.Name == "" || dump.Instance.Name == inst.Name) {
+			dumps = append(dumps, dump)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(dumps, func(i, j int) bool {
+		return dumps[i].Time.After(dumps[j].Time)
+	})
+	return dumps, nil
+}
+
+// Latest 返回 dumps 中 database 最新的备份，dumps 需按时间倒序
+func Latest(dumps []Dump, database string) (Dump, bool) {
+	for _, d := range dumps {
+		if d.Database == database {
+			return d, true
+		}
+	}
+	return Dump{}, false
+}
+
+// Restore 将备份文件解压后通过 mysql 客户端导入目标实例。
+// 备份使用 --databases 导出，导入时会按原库名创建并写入数据库
+func Restore(ctx context.Context, client kubernetes.Interface, dest Instance, conn Conn, path string) error {
+	t, err := connect(ctx, client, dest, conn)
+	if err != nil {
+		return err
+	}
+	return restore(ctx, t, conn.User, path, dest.String())
+}
+
+// restore 将备份文件解压后作为 mysql 客户端的标准输入，target 仅用于错误信息
+func restore(ctx context.Context, ex Executor, user, path, target string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return i18n.Errorf("mysql.not_dump", path, err)
+	}
+	defer gz.Close()
+	if err := ex.Exec(ctx, "mysql -u "+quote(user), gz, nil); err != nil {
+		return i18n.Errorf("mysql.restore_failed", path, target, err)
+	}
+	return nil
+}
+
+// FormatSize 以 1024 为进制输出文件大小
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func contains(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
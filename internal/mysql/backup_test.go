@@ -0,0 +1,166 @@
+package mysql
+
+import (
+	"compress/gzip"
+	"context"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/catalog"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeDump(t *testing.T, dir string, inst Instance, db string, ts time.Time) string {
+	t.Helper()
+	path := DumpPath(dir, inst, db, ts)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	gz.Name, gz.Comment, gz.ModTime = db, inst.String(), ts
+	gz.Write([]byte("-- dump\n"))
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestListBackups(t *testing.T) {
+	dir := t.TempDir()
+	a := Instance{Namespace: "ns", Name: "dol-mysql"}
+	b := Instance{Namespace: "ns", Name: "other"}
+	day1 := time.Date(2025, 1, 17, 21, 32, 7, 0, time.Local)
+	day2 := day1.Add(24 * time.Hour)
+
+	old := writeDump(t, dir, a, "clog", day1)
+	if want := filepath.Join(dir, "20250117", "ns_dol-mysql", "clog", "20250117213207_clog.sql.gz"); old != want {
+		t.Fatalf("DumpPath() = %s, want %s", old, want)
+	}
+	latest := writeDump(t, dir, a, "clog", day2)
+	// 不同实例同一秒备份同名数据库时写入不同的文件
+	if other := writeDump(t, dir, b, "clog", day2); other == latest {
+		t.Fatalf("DumpPath() for %s and %s are both %s", a, b, other)
+	}
+	// 非本工具生成的文件会被跳过
+	os.WriteFile(filepath.Join(dir, "foreign.sql.gz"), []byte("plain"), 0644)
+
+	dumps, err := ListBackups(dir, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dumps) != 2 || dumps[0].Path != latest || dumps[1].Path != old {
+		t.Fatalf("ListBackups() = %+v", dumps)
+	}
+	if d, ok := Latest(dumps, "clog"); !ok || !d.Time.Equal(day2) || d.Instance != a {
+		t.Errorf("Latest() = %+v, %v", d, ok)
+	}
+	if all, _ := ListBackups(dir, Instance{}); len(all) != 3 {
+		t.Errorf("ListBackups(all) returned %d dumps, want 3", len(all))
+	}
+	if none, err := ListBackups(filepath.Join(dir, "missing"), a); err != nil || len(none) != 0 {
+		t.Errorf("ListBackups(missing) = %v, %v", none, err)
+	}
+}
+
+// fakeExecutor 按脚本前缀返回预设输出，记录恢复时读到的内容
+type fakeExecutor struct {
+	outputs  map[string]string
+	restored string
+}
+
+func (f *fakeExecutor) Exec(ctx context.Context, script string, stdin io.Reader, stdout io.Writer) error {
+	if stdin != nil {
+		data, err := io.ReadAll(stdin)
+		f.restored = string(data)
+		return err
+	}
+	for prefix, out := range f.outputs {
+		if strings.Contains(script, prefix) {
+			_, err := io.WriteString(stdout, out)
+			return err
+		}
+	}
+	return nil
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	inst := Instance{Namespace: "ns", Name: "dol-mysql"}
+	path := writeDump(t, dir, inst, "clog", time.Date(2025, 1, 17, 21, 32, 7, 0, time.Local))
+	dump, err := ReadDump(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex := &fakeExecutor{outputs: map[string]string{
+		"information_schema": "a\nb\nc\n",
+		"COUNT(*)":           "10\n20\n",
+		"CHECKSUM TABLE":     "clog.a\t111\nclog.b\t222\n",
+	}}
+	result, err := Verify(context.Background(), ex, "root", dump, 2, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ex.restored != "-- dump\n" {
+		t.Errorf("restored %q", ex.restored)
+	}
+	if result.Tables != 3 || len(result.Samples) != 2 {
+		t.Fatalf("Verify() = %+v", result)
+	}
+	if s := result.Samples[1]; s.Name != "b" || s.Rows != 20 || s.Checksum != "222" {
+		t.Errorf("sample = %+v", s)
+	}
+
+	ex.outputs["CHECKSUM TABLE"] = "clog.a\t111\nclog.b\tNULL\n"
+	if _, err := Verify(context.Background(), ex, "root", dump, 2, time.Second); err == nil {
+		t.Error("NULL checksum should fail")
+	}
+	ex.outputs["information_schema"] = ""
+	if _, err := Verify(context.Background(), ex, "root", dump, 2, time.Second); err == nil {
+		t.Error("empty database should fail")
+	}
+
+	// 索引中没有的备份不会被新增，只返回错误
+	if err := RecordVerification(dir, dump, result, nil); err == nil {
+		t.Error("RecordVerification() for a dump missing from the catalog should fail")
+	}
+	if c, _ := catalog.Load(dir); len(c.Entries) != 0 {
+		t.Errorf("RecordVerification() added entries: %+v", c.Entries)
+	}
+
+	if err := catalog.Record(dir, catalog.Entry{Engine: "mysql", Instance: inst.String(), Databases: []string{"clog"},
+		Path: path, Status: catalog.StatusCompleted, Time: dump.Time}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordVerification(dir, dump, result, nil); err != nil {
+		t.Fatal(err)
+	}
+	c, err := catalog.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := c.Find(path)
+	if !ok || entry.Verification == nil || !entry.Verification.Passed || entry.Verification.Tables != 3 {
+		t.Errorf("catalog entry = %+v", entry)
+	}
+}
+
+func TestConnValidate(t *testing.T) {
+	for env, ok := range map[string]bool{"MYSQL_ROOT_PASSWORD": true, "_pwd1": true, "": false, "1PWD": false, "X; rm -rf /": false, "$(id)": false} {
+		err := Conn{PasswordEnv: env}.Validate()
+		if (err == nil) != ok {
+			t.Errorf("Validate(%q) = %v", env, err)
+		}
+	}
+	ex := DockerExecutor{Container: "mysql", Conn: Conn{PasswordEnv: "X`id`"}}
+	if err := ex.Exec(context.Background(), "true", nil, io.Discard); apperr.ExitCode(err) != apperr.Validation.ExitCode() {
+		t.Errorf("Exec() with invalid PasswordEnv = %v", err)
+	}
+}
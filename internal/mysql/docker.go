@@ -0,0 +1,37 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// DockerExecutor 通过 docker exec 在本地 MySQL 容器中执行命令，用于不依赖集群验证备份
+type DockerExecutor struct {
+	Container string
+	Conn
+}
+
+// Exec 与 Pod 中执行相同，MYSQL_PWD 取自容器的 PasswordEnv 环境变量
+func (d DockerExecutor) Exec(ctx context.Context, script string, stdin io.Reader, stdout io.Writer) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	args := []string{"exec"}
+	if stdin != nil {
+		args = append(args, "-i")
+	}
+	args = append(args, d.Container, "sh", "-c", fmt.Sprintf("MYSQL_PWD=\"$%s\" %s", d.PasswordEnv, script))
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	var stderr strings.Builder
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
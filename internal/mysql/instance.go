@@ -0,0 +1,173 @@
+package mysql
+
+import (
+	"context"
+	"devops_tools/internal/api"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
+	"fmt"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"regexp"
+	"strings"
+)
+
+// Instance 以命名空间和名称定位的 MySQL 实例，名称为 StatefulSet 或 Pod 名称
+type Instance struct {
+	Namespace string
+	Name      string
+}
+
+func (i Instance) String() string {
+	return i.Namespace + "/" + i.Name
+}
+
+// ParseInstance 解析 namespace/name 格式的实例标识
+func ParseInstance(s string) (Instance, bool) {
+	namespace, name, ok := strings.Cut(s, "/")
+	if !ok || namespace == "" || name == "" {
+		return Instance{}, false
+	}
+	return Instance{Namespace: namespace, Name: name}, true
+}
+
+// Conn 在实例容器内执行 mysql 客户端命令的参数，密码从容器的环境变量读取，不经过本地
+type Conn struct {
+	// Container 为空时使用 Pod 的第一个容器
+	Container string
+	User      string
+	// PasswordEnv 容器中保存密码的环境变量名
+	PasswordEnv string
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate 检查 PasswordEnv 是合法的环境变量名，它会被拼接进 sh -c 的命令中
+func (c Conn) Validate() error {
+	if !envNamePattern.MatchString(c.PasswordEnv) {
+		return apperr.ValidationError(i18n.Errorf("mysql.invalid_password_env", c.PasswordEnv))
+	}
+	return nil
+}
+
+// Executor 在 MySQL 容器中执行 shell 命令，命令中的 mysql 客户端已经通过 MYSQL_PWD 获得密码
+type Executor interface {
+	Exec(ctx context.Context, script string, stdin io.Reader, stdout io.Writer) error
+}
+
+// target 实例中被选中执行命令的 Pod
+type target struct {
+	client    kubernetes.Interface
+	namespace string
+	pod       string
+	container string
+	conn      Conn
+}
+
+// connect 查找实例中处于 Running 且 Ready 的 Pod，优先按同名 StatefulSet 的 selector 查找，否则按 Pod 名称查找
+func connect(ctx context.Context, client kubernetes.Interface, inst Instance, conn Conn) (*target, error) {
+	if err := conn.Validate(); err != nil {
+		return nil, err
+	}
+	pod, err := findPod(ctx, client, inst)
+	if err != nil {
+		return nil, err
+	}
+	container := conn.Container
+	if container == "" {
+		container = pod.Spec.Containers[0].Name
+	}
+	return &target{client: client, namespace: pod.Namespace, pod: pod.Name, container: container, conn: conn}, nil
+}
+
+func findPod(ctx context.Context, client kubernetes.Interface, inst Instance) (*corev1.Pod, error) {
+	sts, err := client.AppsV1().StatefulSets(inst.Namespace).Get(ctx, inst.Name, metaV1.GetOptions{})
+	if err == nil {
+		selector, err := metaV1.LabelSelectorAsSelector(sts.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		pods, err := client.CoreV1().Pods(inst.Namespace).List(ctx, metaV1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, err
+		}
+		for i := range pods.Items {
+			if podReady(&pods.Items[i]) {
+				return &pods.Items[i], nil
+			}
+		}
+		return nil, i18n.Errorf("mysql.no_ready_pod", inst)
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	pod, err := client.CoreV1().Pods(inst.Namespace).Get(ctx, inst.Name, metaV1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, i18n.Errorf("mysql.instance_not_found", inst)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !podReady(pod) {
+		return nil, i18n.Errorf("mysql.no_ready_pod", inst)
+	}
+	return pod, nil
+}
+
+func podReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// Exec 在容器内通过 sh -c 执行 mysql 客户端命令，MYSQL_PWD 取自 PasswordEnv 指定的环境变量
+func (t *target) Exec(ctx context.Context, script string, stdin io.Reader, stdout io.Writer) error {
+	var stderr strings.Builder
+	cmd := fmt.Sprintf("MYSQL_PWD=\"$%s\" %s", t.conn.PasswordEnv, script)
+	err := api.Exec(ctx, t.client, t.namespace, t.pod, t.container, []string{"sh", "-c", cmd}, stdin, stdout, &stderr)
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// quote 为 shell 参数加单引号
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// systemDatabases 备份全部数据库时跳过的系统库
+var systemDatabases = map[string]bool{
+	"information_schema": true,
+	"performance_schema": true,
+	"mysql":              true,
+	"sys":                true,
+}
+
+// databases 返回实例中的用户数据库
+func (t *target) databases(ctx context.Context) ([]string, error) {
+	var out strings.Builder
+	if err := t.Exec(ctx, "mysql -N -B -u "+quote(t.conn.User)+" -e 'SHOW DATABASES'", nil, &out); err != nil {
+		return nil, i18n.Errorf("mysql.list_db_failed", err)
+	}
+	var names []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		name := strings.TrimSpace(line)
+		if name != "" && !systemDatabases[name] {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,240 @@
+package mysql
+
+import (
+	"context"
+	"crypto/rand"
+	"devops_tools/internal/catalog"
+	"devops_tools/internal/i18n"
+	"encoding/hex"
+	"fmt"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VerifyOptions verify-backup 的参数
+type VerifyOptions struct {
+	// Namespace 临时实例所在的命名空间，不存在时创建并在结束后删除
+	Namespace string
+	Image     string
+	// Samples 统计行数并执行 CHECKSUM TABLE 的表数量
+	Samples int
+	// Timeout 等待临时实例启动的超时时间
+	Timeout time.Duration
+	// Keep 结束后保留临时实例，便于排查
+	Keep bool
+}
+
+// TableCheck 一张抽样表的检查结果
+type TableCheck struct {
+	Name     string
+	Rows     int64
+	Checksum string
+}
+
+// VerifyResult 恢复验证结果
+type VerifyResult struct {
+	Dump     Dump
+	Tables   int
+	Samples  []TableCheck
+	Duration time.Duration
+}
+
+// Verify 在 ex 对应的空 MySQL 实例中恢复备份，统计表数量，并对前 samples 张表统计行数和执行 CHECKSUM TABLE
+func Verify(ctx context.Context, ex Executor, user string, dump Dump, samples int, timeout time.Duration) (*VerifyResult, error) {
+	start := time.Now()
+	if err := waitReady(ctx, ex, user, timeout); err != nil {
+		return nil, err
+	}
+	if err := restore(ctx, ex, user, dump.Path, "sandbox"); err != nil {
+		return nil, err
+	}
+	result := &VerifyResult{Dump: dump}
+	tables, err := query(ctx, ex, user, "SELECT table_name FROM information_schema.tables WHERE table_schema = '"+
+		strings.ReplaceAll(dump.Database, "'", "''")+"' AND table_type = 'BASE TABLE' ORDER BY table_name")
+	if err != nil {
+		return nil, err
+	}
+	result.Tables = len(tables)
+	if len(tables) == 0 {
+		return result, i18n.Errorf("mysql.verify_no_tables", dump.Database)
+	}
+	if samples > len(tables) {
+		samples = len(tables)
+	}
+	var counts, names []string
+	for _, table := range tables[:samples] {
+		name := ident(dump.Database) + "." + ident(table[0])
+		names = append(names, name)
+		counts = append(counts, "SELECT COUNT(*) FROM "+name)
+	}
+	rows, err := query(ctx, ex, user, strings.Join(counts, "; "))
+	if err != nil {
+		return nil, err
+	}
+	sums, err := query(ctx, ex, user, "CHECKSUM TABLE "+strings.Join(names, ", "))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != samples || len(sums) != samples {
+		return result, i18n.Errorf("mysql.verify_unexpected_output", len(rows), len(sums), samples)
+	}
+	for i, table := range tables[:samples] {
+		check := TableCheck{Name: table[0]}
+		check.Rows, _ = strconv.ParseInt(rows[i][0], 10, 64)
+		if len(sums[i]) > 1 {
+			check.Checksum = sums[i][1]
+		}
+		// CHECKSUM TABLE 对不存在或损坏的表返回 NULL
+		if check.Checksum == "" || check.Checksum == "NULL" {
+			return result, i18n.Errorf("mysql.verify_checksum_failed", table[0])
+		}
+		result.Samples = append(result.Samples, check)
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// waitReady 通过 TCP 连接判断实例就绪。官方镜像初始化期间的临时实例不监听网络，
+// 只检查 socket 会在临时实例重启时中断恢复
+func waitReady(ctx context.Context, ex Executor, user string, timeout time.Duration) error {
+	var lastErr error
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		lastErr = ex.Exec(ctx, "mysql -h 127.0.0.1 -u "+quote(user)+" -e 'SELECT 1'", nil, io.Discard)
+		return lastErr == nil, nil
+	})
+	if err != nil {
+		return i18n.Errorf("mysql.verify_not_ready", lastErr)
+	}
+	return nil
+}
+
+// query 执行 SQL 并按行和制表符拆分输出
+func query(ctx context.Context, ex Executor, user, sql string) ([][]string, error) {
+	var out strings.Builder
+	if err := ex.Exec(ctx, "mysql -N -B -u "+quote(user)+" -e "+quote(sql), nil, &out); err != nil {
+		return nil, err
+	}
+	var rows [][]string
+	for _, line := range strings.Split(strings.TrimRight(out.String(), "\n"), "\n") {
+		if line != "" {
+			rows = append(rows, strings.Split(line, "\t"))
+		}
+	}
+	return rows, nil
+}
+
+// ident 为 MySQL 标识符加反引号
+func ident(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// VerifyInCluster 在 opts.Namespace 中创建临时 MySQL Pod，恢复备份并检查，结束后删除 Pod 以及新建的命名空间
+func VerifyInCluster(ctx context.Context, client kubernetes.Interface, dump Dump, opts VerifyOptions, progress io.Writer) (*VerifyResult, error) {
+	created, err := ensureNamespace(ctx, client, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	password := make([]byte, 16)
+	if _, err := rand.Read(password); err != nil {
+		return nil, err
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      "mysql-verify-" + strconv.FormatInt(time.Now().Unix(), 10),
+			Namespace: opts.Namespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "devops-tool", "app.kubernetes.io/component": "mysql-verify"},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:  "mysql",
+				Image: opts.Image,
+				Env:   []corev1.EnvVar{{Name: "MYSQL_ROOT_PASSWORD", Value: hex.EncodeToString(password)}},
+			}},
+		},
+	}
+	if pod, err = client.CoreV1().Pods(opts.Namespace).Create(ctx, pod, metaV1.CreateOptions{}); err != nil {
+		return nil, err
+	}
+	fmt.Fprint(progress, i18n.T("mysql.verify_pod_created", pod.Namespace, pod.Name))
+	if opts.Keep {
+		defer fmt.Fprint(progress, i18n.T("mysql.verify_kept", pod.Namespace, pod.Name))
+	} else {
+		defer teardown(client, pod, created, progress)
+	}
+
+	err = wait.PollUntilContextTimeout(ctx, 2*time.Second, opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		p, err := client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metaV1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if p.Status.Phase == corev1.PodFailed || p.Status.Phase == corev1.PodSucceeded {
+			return false, i18n.Errorf("mysql.verify_pod_exited", p.Status.Phase)
+		}
+		return p.Status.Phase == corev1.PodRunning, nil
+	})
+	if err != nil {
+		return nil, i18n.Errorf("mysql.verify_not_ready", err)
+	}
+	t := &target{client: client, namespace: pod.Namespace, pod: pod.Name, container: "mysql", conn: Conn{User: "root", PasswordEnv: "MYSQL_ROOT_PASSWORD"}}
+	return Verify(ctx, t, "root", dump, opts.Samples, opts.Timeout)
+}
+
+// ensureNamespace 命名空间不存在时创建，返回是否由本次创建
+func ensureNamespace(ctx context.Context, client kubernetes.Interface, name string) (bool, error) {
+	_, err := client.CoreV1().Namespaces().Get(ctx, name, metaV1.GetOptions{})
+	if err == nil {
+		return false, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	ns := &corev1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: name, Labels: map[string]string{"app.kubernetes.io/managed-by": "devops-tool"}}}
+	if _, err := client.CoreV1().Namespaces().Create(ctx, ns, metaV1.CreateOptions{}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// teardown 删除临时 Pod 和本次创建的命名空间，使用独立的 context，原 context 超时或取消时也能清理
+func teardown(client kubernetes.Interface, pod *corev1.Pod, deleteNamespace bool, progress io.Writer) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	grace := int64(0)
+	if err := client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metaV1.DeleteOptions{GracePeriodSeconds: &grace}); err != nil && !apierrors.IsNotFound(err) {
+		fmt.Fprint(progress, i18n.T("mysql.verify_cleanup_failed", pod.Namespace+"/"+pod.Name, err))
+	}
+	if !deleteNamespace {
+		return
+	}
+	if err := client.CoreV1().Namespaces().Delete(ctx, pod.Namespace, metaV1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		fmt.Fprint(progress, i18n.T("mysql.verify_cleanup_failed", pod.Namespace, err))
+	}
+}
+
+// RecordVerification 将验证结果写入 root 下备份目录索引中已有的记录。
+// 索引中没有该备份时返回错误，不新增记录，避免 Prune 删除备份根目录之外的文件
+func RecordVerification(root string, dump Dump, result *VerifyResult, verifyErr error) error {
+	v := &catalog.Verification{Time: time.Now(), Passed: verifyErr == nil}
+	if result != nil {
+		v.Tables = result.Tables
+	}
+	if verifyErr != nil {
+		v.Message = verifyErr.Error()
+	}
+	return catalog.Update(root, func(c *catalog.Catalog) error {
+		entry, ok := c.Find(dump.Path)
+		if !ok {
+			return i18n.Errorf("catalog.entry_not_found", dump.Path, root)
+		}
+		entry.Verification = v
+		return nil
+	})
+}
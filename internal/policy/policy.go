@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"devops_tools/internal/cluster"
+	"devops_tools/internal/i18n"
+	_ "embed"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"time"
+)
+
+//go:embed crd.yaml
+var crdManifest []byte
+
+// CRDManifest 返回 StorageCleanupPolicy 的 CustomResourceDefinition YAML
+func CRDManifest() []byte {
+	return crdManifest
+}
+
+// FromUnstructured 将 dynamic client 返回的对象转换为策略
+func FromUnstructured(u *unstructured.Unstructured) (*StorageCleanupPolicy, error) {
+	p := &StorageCleanupPolicy{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ToUnstructured 将策略转换为 dynamic client 可提交的对象
+func (p *StorageCleanupPolicy) ToUnstructured() (*unstructured.Unstructured, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(p)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: obj}, nil
+}
+
+// Candidates 从快照的清理候选中筛选出本策略允许清理的资源
+func (p *StorageCleanupPolicy) Candidates(snap *cluster.Snapshot, now time.Time) ([]cluster.CleanupCandidate, error) {
+	selector := labels.Everything()
+	if p.Spec.Selector != nil {
+		var err error
+		selector, err = metaV1.LabelSelectorAsSelector(p.Spec.Selector)
+		if err != nil {
+			return nil, i18n.Errorf("policy.invalid_selector", err)
+		}
+	}
+	storageClasses := toSet(p.Spec.StorageClassNames)
+	namespaces := toSet(p.Spec.ClaimNamespaces)
+
+	// 未列出任何原因的策略不清理任何资源，NodeMissing 只有在策略中显式列出时才会清理
+	if len(p.Spec.Reasons) == 0 {
+		return nil, nil
+	}
+	var matched []cluster.CleanupCandidate
+	for _, c := range snap.CleanupCandidates(p.Spec.Reasons) {
+		accessor, err := meta.Accessor(c.Object)
+		if err != nil {
+			return nil, err
+		}
+		if !selector.Matches(labels.Set(accessor.GetLabels())) {
+			continue
+		}
+		if now.Sub(accessor.GetCreationTimestamp().Time) < p.Spec.MinAge.Duration {
+			continue
+		}
+		scName, claimNamespace := c.Name, ""
+		if pv, ok := c.Object.(*corev1.PersistentVolume); ok {
+			scName = pv.Spec.StorageClassName
+			if pv.Spec.ClaimRef != nil {
+				claimNamespace = pv.Spec.ClaimRef.Namespace
+			}
+		}
+		if len(storageClasses) > 0 && !storageClasses[scName] {
+			continue
+		}
+		if len(namespaces) > 0 && !namespaces[claimNamespace] {
+			continue
+		}
+		matched = append(matched, c)
+	}
+	return matched, nil
+}
+
+func toSet[T comparable](items []T) map[T]bool {
+	set := make(map[T]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
@@ -0,0 +1,84 @@
+package policy
+
+import (
+	"context"
+	"devops_tools/internal/cluster"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestCandidates(t *testing.T) {
+	now := time.Now()
+	pv := func(name, sc string, phase corev1.PersistentVolumePhase, age time.Duration, labels map[string]string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metaV1.ObjectMeta{Name: name, Labels: labels, CreationTimestamp: metaV1.NewTime(now.Add(-age))},
+			Spec:       corev1.PersistentVolumeSpec{StorageClassName: sc},
+			Status:     corev1.PersistentVolumeStatus{Phase: phase},
+		}
+	}
+	available := pv("available", "local", corev1.VolumeAvailable, 48*time.Hour, map[string]string{"team": "a"})
+	young := pv("young", "local", corev1.VolumeAvailable, time.Hour, nil)
+	missing := pv("pvc-missing", "nfs", corev1.VolumeReleased, 48*time.Hour, nil)
+	missing.Spec.ClaimRef = &corev1.ObjectReference{Namespace: "app", Name: "data"}
+	// 绑定节点已不存在的 local PV 只有在策略中显式列出 NodeMissing 时才会清理
+	stale := pv("stale", "local", corev1.VolumeBound, 48*time.Hour, nil)
+	stale.Spec.Local = &corev1.LocalVolumeSource{Path: "/data"}
+	stale.Spec.NodeAffinity = &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+		MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "kubernetes.io/hostname", Values: []string{"gone"}}},
+	}}}}
+	unused := &storagev1.StorageClass{ObjectMeta: metaV1.ObjectMeta{Name: "unused", CreationTimestamp: metaV1.NewTime(now.Add(-48 * time.Hour))}}
+	objects := []runtime.Object{available, young, missing, stale, unused,
+		&storagev1.StorageClass{ObjectMeta: metaV1.ObjectMeta{Name: "local"}},
+		&storagev1.StorageClass{ObjectMeta: metaV1.ObjectMeta{Name: "nfs"}},
+	}
+	snap, err := cluster.LoadSnapshot(context.Background(), fake.NewSimpleClientset(objects...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := []cluster.CleanupReason{cluster.ReasonUnusedStorageClass, cluster.ReasonAvailable, cluster.ReasonPVCMissing, cluster.ReasonNodeMissing}
+	cases := []struct {
+		name string
+		spec StorageCleanupPolicySpec
+		want []string
+	}{
+		{"all reasons", StorageCleanupPolicySpec{Reasons: all}, []string{"available", "pvc-missing", "stale", "unused", "young"}},
+		{"without node missing", StorageCleanupPolicySpec{Reasons: all[:3]}, []string{"available", "pvc-missing", "unused", "young"}},
+		{"reason filter", StorageCleanupPolicySpec{Reasons: []cluster.CleanupReason{cluster.ReasonAvailable}}, []string{"available", "young"}},
+		{"node missing only", StorageCleanupPolicySpec{Reasons: []cluster.CleanupReason{cluster.ReasonNodeMissing}}, []string{"stale"}},
+		{"no reasons", StorageCleanupPolicySpec{}, nil},
+		{"selector", StorageCleanupPolicySpec{Reasons: all, Selector: &metaV1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}}, []string{"available"}},
+		{"min age", StorageCleanupPolicySpec{Reasons: all, MinAge: metaV1.Duration{Duration: 24 * time.Hour}}, []string{"available", "pvc-missing", "stale", "unused"}},
+		{"storage class", StorageCleanupPolicySpec{Reasons: all, StorageClassNames: []string{"nfs", "unused"}}, []string{"pvc-missing", "unused"}},
+		{"claim namespace", StorageCleanupPolicySpec{Reasons: all, ClaimNamespaces: []string{"app"}}, []string{"pvc-missing"}},
+	}
+	for _, tc := range cases {
+		p := &StorageCleanupPolicy{Spec: tc.spec}
+		candidates, err := p.Candidates(snap, now)
+		if err != nil {
+			t.Fatalf("%s: Candidates() error = %v", tc.name, err)
+		}
+		var got []string
+		for _, c := range candidates {
+			got = append(got, c.Name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Candidates() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	bad := &StorageCleanupPolicy{Spec: StorageCleanupPolicySpec{Reasons: all, Selector: &metaV1.LabelSelector{
+		MatchExpressions: []metaV1.LabelSelectorRequirement{{Key: "team", Operator: "Bad"}},
+	}}}
+	if _, err := bad.Candidates(snap, now); err == nil {
+		t.Error("expected error for invalid selector")
+	}
+}
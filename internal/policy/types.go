@@ -0,0 +1,57 @@
+package policy
+
+import (
+	"devops_tools/internal/cluster"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	Group   = "devops-tool.io"
+	Version = "v1alpha1"
+	Kind    = "StorageCleanupPolicy"
+)
+
+// GroupVersionResource StorageCleanupPolicy 的 GVR，通过 dynamic client 访问
+var GroupVersionResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "storagecleanuppolicies"}
+
+// StorageCleanupPolicy 集群级别的存储清理策略，由控制器按 Spec.Schedule 执行
+type StorageCleanupPolicy struct {
+	metaV1.TypeMeta   `json:",inline"`
+	metaV1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StorageCleanupPolicySpec   `json:"spec"`
+	Status StorageCleanupPolicyStatus `json:"status,omitempty"`
+}
+
+type StorageCleanupPolicySpec struct {
+	// Selector 按标签筛选 PV 和 StorageClass，为空时匹配全部
+	Selector *metaV1.LabelSelector `json:"selector,omitempty"`
+	// StorageClassNames 只处理这些 StorageClass 及其下的 PV，为空时不限制
+	StorageClassNames []string `json:"storageClassNames,omitempty"`
+	// ClaimNamespaces 只处理 ClaimRef 指向这些命名空间的 PV，为空时不限制
+	ClaimNamespaces []string `json:"claimNamespaces,omitempty"`
+	// MinAge 资源创建时间超过该时长才会被清理
+	MinAge metaV1.Duration `json:"minAge,omitempty"`
+	// Reasons 允许清理的原因
+	Reasons []cluster.CleanupReason `json:"reasons"`
+	Backup  BackupSink              `json:"backup,omitempty"`
+	DryRun  bool                    `json:"dryRun,omitempty"`
+	// Schedule 标准 5 段 cron 表达式
+	Schedule string `json:"schedule"`
+}
+
+// BackupSink 删除前备份资源 YAML 的位置
+type BackupSink struct {
+	// Dir 控制器容器内的目录，通常挂载备份 PVC
+	Dir string `json:"dir,omitempty"`
+}
+
+type StorageCleanupPolicyStatus struct {
+	ObservedGeneration int64        `json:"observedGeneration,omitempty"`
+	LastRunTime        *metaV1.Time `json:"lastRunTime,omitempty"`
+	Candidates         int          `json:"candidates"`
+	Deleted            int          `json:"deleted"`
+	Failures           int          `json:"failures"`
+	Message            string       `json:"message,omitempty"`
+}
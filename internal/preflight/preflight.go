@@ -0,0 +1,169 @@
+package preflight
+
+import (
+	"context"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/i18n"
+	"fmt"
+	"io"
+	authv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Permission 一次 SelfSubjectAccessReview 检查的权限，Namespace 为空表示集群范围
+type Permission struct {
+	Group       string
+	Resource    string
+	Subresource string
+	Verb        string
+	Namespace   string
+}
+
+func (p Permission) resourceName() string {
+	name := p.Resource
+	if p.Subresource != "" {
+		name += "/" + p.Subresource
+	}
+	if p.Group != "" {
+		name += "." + p.Group
+	}
+	if p.Namespace != "" {
+		name += " (" + p.Namespace + ")"
+	}
+	return name
+}
+
+// Result 权限检查结果
+type Result struct {
+	Permission
+	Allowed bool
+	Reason  string
+}
+
+// MissingError 存在未授权的权限
+type MissingError struct {
+	Missing []Result
+}
+
+func (e *MissingError) Error() string {
+	perms := make([]string, 0, len(e.Missing))
+	for _, r := range e.Missing {
+		perms = append(perms, r.Verb+" "+r.resourceName())
+	}
+	return i18n.T("preflight.missing", len(e.Missing), strings.Join(perms, ", "))
+}
+
+// FromRules 将 RBAC 规则展开为逐项检查的权限，namespace 为空时按集群范围检查
+func FromRules(rules []rbacv1.PolicyRule, namespace string) []Permission {
+	var perms []Permission
+	for _, rule := range rules {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				res, sub, _ := strings.Cut(resource, "/")
+				for _, verb := range rule.Verbs {
+					perms = append(perms, Permission{Group: group, Resource: res, Subresource: sub, Verb: verb, Namespace: namespace})
+				}
+			}
+		}
+	}
+	return perms
+}
+
+// Check 对每项权限发起 SelfSubjectAccessReview
+func Check(ctx context.Context, client kubernetes.Interface, perms []Permission) ([]Result, error) {
+	results := make([]Result, 0, len(perms))
+	for _, p := range perms {
+		review := &authv1.SelfSubjectAccessReview{
+			Spec: authv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authv1.ResourceAttributes{
+					Namespace:   p.Namespace,
+					Verb:        p.Verb,
+					Group:       p.Group,
+					Resource:    p.Resource,
+					Subresource: p.Subresource,
+				},
+			},
+		}
+		resp, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metaV1.CreateOptions{})
+		if err != nil {
+			return nil, i18n.Errorf("preflight.check_failed", p.Verb, p.resourceName(), err)
+		}
+		results = append(results, Result{Permission: p, Allowed: resp.Status.Allowed, Reason: resp.Status.Reason})
+	}
+	return results, nil
+}
+
+// Missing 返回未授权的检查结果
+func Missing(results []Result) []Result {
+	var missing []Result
+	for _, r := range results {
+		if !r.Allowed {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// Require 检查全部权限，有缺失时向 w 打印权限矩阵并返回 Permission 类别的 *MissingError
+func Require(ctx context.Context, client kubernetes.Interface, perms []Permission, w io.Writer) error {
+	results, err := Check(ctx, client, perms)
+	if err != nil {
+		return err
+	}
+	missing := Missing(results)
+	if len(missing) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, i18n.T("preflight.failed_header"))
+	if err := PrintMatrix(w, results); err != nil {
+		return err
+	}
+	return apperr.PermissionError(&MissingError{Missing: missing})
+}
+
+// PrintMatrix 以资源为行、动作为列打印检查结果
+func PrintMatrix(w io.Writer, results []Result) error {
+	verbSet := make(map[string]bool)
+	cells := make(map[string]map[string]bool)
+	var rows []string
+	for _, r := range results {
+		verbSet[r.Verb] = true
+		name := r.resourceName()
+		if cells[name] == nil {
+			cells[name] = make(map[string]bool)
+			rows = append(rows, name)
+		}
+		cells[name][r.Verb] = r.Allowed
+	}
+	verbs := make([]string, 0, len(verbSet))
+	for verb := range verbSet {
+		verbs = append(verbs, verb)
+	}
+	sort.Strings(verbs)
+	sort.Strings(rows)
+
+	tw := new(tabwriter.Writer)
+	tw.Init(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\n", i18n.Header("RESOURCE"), strings.ToUpper(strings.Join(verbs, "\t")))
+	for _, name := range rows {
+		line := []string{name}
+		for _, verb := range verbs {
+			allowed, required := cells[name][verb]
+			switch {
+			case !required:
+				line = append(line, "-")
+			case allowed:
+				line = append(line, "yes")
+			default:
+				line = append(line, "NO")
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(line, "\t"))
+	}
+	return tw.Flush()
+}
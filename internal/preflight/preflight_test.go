@@ -0,0 +1,48 @@
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	authv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"strings"
+	"testing"
+)
+
+func TestRequire(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	// 只允许 list
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Verb == "list"
+		return true, review, nil
+	})
+	perms := FromRules([]rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"persistentvolumes"}, Verbs: []string{"list", "delete"}},
+		{APIGroups: []string{"storage.k8s.io"}, Resources: []string{"storageclasses"}, Verbs: []string{"list"}},
+	}, "")
+
+	var out bytes.Buffer
+	err := Require(context.Background(), client, perms, &out)
+	var missing *MissingError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Require() error = %v, want *MissingError", err)
+	}
+	if len(missing.Missing) != 1 || missing.Missing[0].Resource != "persistentvolumes" || missing.Missing[0].Verb != "delete" {
+		t.Errorf("missing = %+v", missing.Missing)
+	}
+	want := map[string]string{
+		"persistentvolumes":             "NO yes",
+		"storageclasses.storage.k8s.io": "- yes",
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n")[2:] {
+		fields := strings.Fields(line)
+		if got := strings.Join(fields[1:], " "); got != want[fields[0]] {
+			t.Errorf("matrix row %s = %q, want %q", fields[0], got, want[fields[0]])
+		}
+	}
+}
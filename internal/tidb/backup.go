@@ -0,0 +1,300 @@
+package tidb
+
+import (
+	"context"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/catalog"
+	"devops_tools/internal/i18n"
+	"encoding/json"
+	"fmt"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"net"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tool 执行备份或恢复的 TiDB 工具
+type Tool string
+
+const (
+	ToolBR        Tool = "br"
+	ToolDumpling  Tool = "dumpling"
+	toolLightning Tool = "tidb-lightning"
+)
+
+// DefaultVersion 无法从 TidbCluster 读取版本时使用的工具镜像版本
+const DefaultVersion = "v7.5.0"
+
+// recordKey 备份记录 ConfigMap 中保存元数据的 key
+const recordKey = "backup.json"
+
+// ParseTool 校验 --tool 参数
+func ParseTool(s string) (Tool, error) {
+	switch Tool(s) {
+	case ToolBR, ToolDumpling:
+		return Tool(s), nil
+	}
+	return "", apperr.ValidationError(i18n.Errorf("tidb.invalid_tool", s))
+}
+
+// BackupOptions backup 命令的参数
+type BackupOptions struct {
+	JobOptions
+	Tool Tool
+	// Databases 为空或包含 all 时备份全部非系统库
+	Databases []string
+	// Storage BR 的备份存储地址，例如 s3://bucket/prefix。为空时使用 local://<备份目录>，
+	// 此时 Root 必须是 TiKV 节点也挂载在同一路径的共享存储
+	Storage string
+}
+
+// Record 一次备份的元数据，保存在实例命名空间中与备份 Job 同名的 ConfigMap 中
+type Record struct {
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Instance  string    `json:"instance"`
+	Tool      Tool      `json:"tool"`
+	Databases []string  `json:"databases,omitempty"`
+	TSO       uint64    `json:"tso"`
+	Size      int64     `json:"size"`
+	Checksum  string    `json:"checksum,omitempty"`
+	Path      string    `json:"path"`
+	Time      time.Time `json:"time"`
+}
+
+// Entry 转换为备份目录索引中的记录。root 为 Job 中备份 PVC 的挂载路径，
+// dir 为同一 PVC 在本机的挂载目录，PVC 中的备份路径改写为 dir 下的路径，
+// 远端存储地址保持不变
+func (r *Record) Entry(root, dir string) catalog.Entry {
+	p, scheme := r.Path, ""
+	if local, ok := strings.CutPrefix(p, "local://"); ok {
+		p, scheme = local, "local://"
+	}
+	if !strings.Contains(p, "://") {
+		if rel, ok := strings.CutPrefix(p, path.Clean(root)+"/"); ok {
+			p = filepath.Join(dir, filepath.FromSlash(rel))
+		}
+	}
+	return catalog.Entry{
+		Engine:    "tidb",
+		Instance:  r.Namespace + "/" + r.Instance,
+		Databases: r.Databases,
+		Path:      scheme + p,
+		Size:      r.Size,
+		Checksum:  r.Checksum,
+		Status:    catalog.StatusCompleted,
+		Time:      r.Time,
+	}
+}
+
+// Backup 在实例命名空间中启动 BR 或 Dumpling Job，等待完成后记录备份元数据
+func Backup(ctx context.Context, client kubernetes.Interface, c *Cluster, opts BackupOptions, progress io.Writer) (*Record, error) {
+	now := time.Now()
+	rec := &Record{
+		Name:      c.Instance.Name + "-backup-" + now.Format("20060102150405"),
+		Namespace: c.Instance.Namespace,
+		Instance:  c.Instance.Name,
+		Tool:      opts.Tool,
+		Time:      now,
+	}
+	if len(opts.Databases) > 0 && !contains(opts.Databases, "all") {
+		rec.Databases = opts.Databases
+	}
+	dir := path.Join(opts.Root, now.Format("20060102"), now.Format("20060102150405")+"_"+c.Instance.Name)
+
+	// Dumpling 以及使用默认 local 存储的 BR 需要把备份写入 PVC
+	if opts.PVC == "" && (opts.Tool != ToolBR || opts.Storage == "") {
+		return nil, apperr.ValidationError(i18n.Errorf("tidb.pvc_required", opts.Tool))
+	}
+	var script string
+	switch opts.Tool {
+	case ToolBR:
+		rec.Path = opts.Storage
+		if rec.Path == "" {
+			rec.Path = "local://" + dir
+		}
+		script = brBackupScript(c.PD, rec.Path, rec.Databases)
+	default:
+		rec.Path = dir
+		host, port, err := net.SplitHostPort(c.TiDB)
+		if err != nil {
+			return nil, err
+		}
+		script = dumplingScript(host, port, opts.User, dir, rec.Databases)
+	}
+	job, err := newJob(rec.Namespace, rec.Name, rec.Instance, c.image(opts.Tool, opts.Image), script, opts.JobOptions)
+	if err != nil {
+		return nil, apperr.ValidationError(err)
+	}
+	logs, err := runJob(ctx, client, job, opts.Timeout, progress)
+	if err != nil {
+		return nil, err
+	}
+	meta, ok := parseMeta(logs)
+	if !ok {
+		return nil, i18n.Errorf("tidb.meta_missing", rec.Namespace, rec.Name)
+	}
+	rec.TSO, rec.Size, rec.Checksum = meta.TSO, meta.Size, meta.Checksum
+	if err := saveRecord(ctx, client, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Restore 将备份恢复到 dest。Job 运行在备份所在的命名空间以便挂载备份 PVC，
+// 通过 Service 全限定域名访问其他命名空间中的目标集群
+func Restore(ctx context.Context, client kubernetes.Interface, dest *Cluster, rec *Record, opts JobOptions, progress io.Writer) error {
+	var script string
+	tool := rec.Tool
+	switch rec.Tool {
+	case ToolBR:
+		script = brRestoreScript(dest.PD, rec.Path, rec.Databases)
+	default:
+		if opts.PVC == "" {
+			return apperr.ValidationError(i18n.Errorf("tidb.pvc_required", toolLightning))
+		}
+		tool = toolLightning
+		host, port, err := net.SplitHostPort(dest.TiDB)
+		if err != nil {
+			return err
+		}
+		script = lightningScript(host, port, opts.User, rec.Path)
+	}
+	name := dest.Instance.Name + "-restore-" + time.Now().Format("20060102150405")
+	job, err := newJob(rec.Namespace, name, dest.Instance.Name, dest.image(tool, opts.Image), script, opts)
+	if err != nil {
+		return apperr.ValidationError(err)
+	}
+	_, err = runJob(ctx, client, job, opts.Timeout, progress)
+	return err
+}
+
+// ListBackups 列出命名空间中 instance 的备份记录，instance 为空时列出全部，按时间倒序
+func ListBackups(ctx context.Context, client kubernetes.Interface, namespace, instance string) ([]Record, error) {
+	selector := managedByLabel + "=" + managedBy + "," + instanceLabel
+	if instance != "" {
+		selector += "=" + instance
+	}
+	cms, err := client.CoreV1().ConfigMaps(namespace).List(ctx, metaV1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	for _, cm := range cms.Items {
+		var rec Record
+		if err := json.Unmarshal([]byte(cm.Data[recordKey]), &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.After(records[j].Time)
+	})
+	return records, nil
+}
+
+// FindBackup 按备份路径或存储地址查找记录
+func FindBackup(records []Record, backupPath string) (*Record, bool) {
+	for i := range records {
+		if records[i].Path == backupPath || strings.TrimSuffix(records[i].Path, "/") == strings.TrimSuffix(backupPath, "/") {
+			return &records[i], true
+		}
+	}
+	return nil, false
+}
+
+func saveRecord(ctx context.Context, client kubernetes.Interface, rec *Record) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      rec.Name,
+			Namespace: rec.Namespace,
+			Labels:    map[string]string{managedByLabel: managedBy, instanceLabel: rec.Instance},
+		},
+		Data: map[string]string{recordKey: string(data)},
+	}
+	if _, err := client.CoreV1().ConfigMaps(rec.Namespace).Create(ctx, cm, metaV1.CreateOptions{}); err != nil {
+		return i18n.Errorf("tidb.record_failed", rec.Name, err)
+	}
+	return nil
+}
+
+// filterArgs 将数据库列表转换为 BR/Dumpling 的表过滤参数
+func filterArgs(flag string, databases []string) string {
+	var b strings.Builder
+	for _, db := range databases {
+		fmt.Fprintf(&b, " %s %s", flag, quote(db+".*"))
+	}
+	return b.String()
+}
+
+// metaEcho 输出 parseMeta 读取的最后一行日志
+const metaEcho = `echo "` + metaPrefix + ` tso=${TSO:-0} size=${SIZE:-0} checksum=${CHECKSUM}"`
+
+// checksumLine 计算目录内 sha256sum 按路径排序后输出内容的 sha256，与 catalog.Entry.Checksum 一致
+func checksumLine(dir string) string {
+	return "CHECKSUM=$(cd " + quote(dir) + " && find . -type f | sort | xargs sha256sum | sha256sum | cut -d' ' -f1)"
+}
+
+func brBackupScript(pd, storage string, databases []string) string {
+	lines := []string{
+		"set -e",
+		"/br backup full --pd " + quote(pd) + " --storage " + quote(storage) + filterArgs("--filter", databases),
+		"TSO=$(/br validate decode --field=end-version --storage " + quote(storage) + " | tail -n 1)",
+	}
+	if dir, ok := strings.CutPrefix(storage, "local://"); ok {
+		lines = append(lines, "SIZE=$(du -sb "+quote(dir)+" | cut -f1)", checksumLine(dir))
+	}
+	return strings.Join(append(lines, metaEcho), "\n")
+}
+
+func brRestoreScript(pd, storage string, databases []string) string {
+	return strings.Join([]string{
+		"set -e",
+		"/br restore full --pd " + quote(pd) + " --storage " + quote(storage) + filterArgs("--filter", databases),
+	}, "\n")
+}
+
+func dumplingScript(host, port, user, dir string, databases []string) string {
+	return strings.Join([]string{
+		"set -e",
+		"mkdir -p " + quote(dir),
+		"/dumpling -h " + quote(host) + " -P " + port + " -u " + quote(user) + ` -p "$TIDB_PASSWORD" -o ` + quote(dir) +
+			" --filetype sql -t 4" + filterArgs("-f", databases),
+		`TSO=$(sed -n 's/.*Pos: *\([0-9][0-9]*\).*/\1/p' ` + quote(dir+"/metadata") + " | head -n 1)",
+		"SIZE=$(du -sb " + quote(dir) + " | cut -f1)",
+		checksumLine(dir),
+		metaEcho,
+	}, "\n")
+}
+
+func lightningScript(host, port, user, dir string) string {
+	return strings.Join([]string{
+		"set -e",
+		"/tidb-lightning --backend tidb --tidb-host " + quote(host) + " --tidb-port " + port + " --tidb-user " + quote(user) +
+			` --tidb-password "$TIDB_PASSWORD" -d ` + quote(dir) + " --check-requirements=false",
+	}, "\n")
+}
+
+// quote 为 shell 参数加单引号
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func contains(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
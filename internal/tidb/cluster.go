@@ -0,0 +1,88 @@
+package tidb
+
+import (
+	"context"
+	"devops_tools/internal/i18n"
+	"devops_tools/internal/mysql"
+	"fmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// TidbClusterResource tidb-operator 的 TidbCluster GVR
+var TidbClusterResource = schema.GroupVersionResource{Group: "pingcap.com", Version: "v1alpha1", Resource: "tidbclusters"}
+
+// Cluster 发现到的 TiDB 集群访问地址，地址使用 Service 的集群内全限定域名，可以跨命名空间访问
+type Cluster struct {
+	Instance mysql.Instance
+	// PD PD client 地址 host:port
+	PD string
+	// TiDB MySQL 协议地址 host:port
+	TiDB string
+	// Version TidbCluster 的 spec.version，未使用 tidb-operator 部署时为空
+	Version string
+}
+
+// Discover 优先从同名 TidbCluster 读取版本并按 tidb-operator 的命名约定得到 PD 和 TiDB 地址，
+// 无法读取 TidbCluster 时直接查找 <name>-pd 和 <name>-tidb Service
+func Discover(ctx context.Context, client kubernetes.Interface, dyn dynamic.Interface, inst mysql.Instance) (*Cluster, error) {
+	c := &Cluster{Instance: inst}
+	if dyn != nil {
+		tc, err := dyn.Resource(TidbClusterResource).Namespace(inst.Namespace).Get(ctx, inst.Name, metaV1.GetOptions{})
+		switch {
+		case err == nil:
+			c.Version, _, _ = unstructured.NestedString(tc.Object, "spec", "version")
+		case apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || meta.IsNoMatchError(err):
+			// 没有 TidbCluster、未安装 CRD 或无权读取时只使用 Service
+		default:
+			return nil, err
+		}
+	}
+	var err error
+	if c.PD, err = serviceAddress(ctx, client, inst.Namespace, inst.Name+"-pd", "client", 2379); err != nil {
+		return nil, err
+	}
+	if c.TiDB, err = serviceAddress(ctx, client, inst.Namespace, inst.Name+"-tidb", "mysql", 4000); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// serviceAddress 返回 Service 中名为 portName 的端口地址，不存在该端口名时使用第一个端口
+func serviceAddress(ctx context.Context, client kubernetes.Interface, namespace, name, portName string, defaultPort int32) (string, error) {
+	svc, err := client.CoreV1().Services(namespace).Get(ctx, name, metaV1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", i18n.Errorf("tidb.service_not_found", namespace, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	port := defaultPort
+	if len(svc.Spec.Ports) > 0 {
+		port = svc.Spec.Ports[0].Port
+	}
+	for _, p := range svc.Spec.Ports {
+		if p.Name == portName {
+			port = p.Port
+			break
+		}
+	}
+	return fmt.Sprintf("%s.%s.svc:%d", name, namespace, port), nil
+}
+
+// image 返回工具镜像，未指定时使用与集群版本一致的 pingcap 官方镜像
+func (c *Cluster) image(tool Tool, override string) string {
+	if override != "" {
+		return override
+	}
+	version := c.Version
+	if version == "" {
+		version = DefaultVersion
+	}
+	return "pingcap/" + string(tool) + ":" + version
+}
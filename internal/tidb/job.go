@@ -0,0 +1,182 @@
+package tidb
+
+import (
+	"bufio"
+	"context"
+	"devops_tools/internal/i18n"
+	"fmt"
+	"io"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedBy      = "devops-tool"
+	instanceLabel  = "devops-tool/tidb-instance"
+	// metaPrefix 备份 Job 最后一行日志的前缀，后面是 tso=<TSO> size=<字节数> checksum=<sha256>
+	metaPrefix = "DEVOPS_TOOL_META"
+)
+
+// JobOptions 备份和恢复 Job 共用的参数
+type JobOptions struct {
+	// Image 为空时使用与集群版本一致的 pingcap/<tool> 镜像
+	Image string
+	// PVC 挂载到 Root 的备份 PVC，必须与 Job 位于同一命名空间
+	PVC string
+	// Root 备份根目录，Dumpling 备份写入 <Root>/<YYYYMMDD>/<YYYYMMDDHHMMSS>_<name>
+	Root string
+	User string
+	// PasswordSecret <secret>/<key> 格式，为空时使用空密码
+	PasswordSecret string
+	// Timeout 等待 Job 完成的超时时间
+	Timeout time.Duration
+}
+
+// newJob 构造执行 script 的一次性 Job，失败后不重试，完成一天后自动删除
+func newJob(namespace, name string, inst string, image, script string, opts JobOptions) (*batchv1.Job, error) {
+	labels := map[string]string{managedByLabel: managedBy, instanceLabel: inst}
+	container := corev1.Container{
+		Name:    "tidb-job",
+		Image:   image,
+		Command: []string{"sh", "-c", script},
+	}
+	if opts.PasswordSecret != "" {
+		secret, key, ok := strings.Cut(opts.PasswordSecret, "/")
+		if !ok || secret == "" || key == "" {
+			return nil, i18n.Errorf("tidb.invalid_secret", opts.PasswordSecret)
+		}
+		container.Env = []corev1.EnvVar{{
+			Name: "TIDB_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+				Key:                  key,
+			}},
+		}}
+	}
+	podSpec := corev1.PodSpec{RestartPolicy: corev1.RestartPolicyNever, Containers: []corev1.Container{container}}
+	if opts.PVC != "" {
+		podSpec.Volumes = []corev1.Volume{{
+			Name:         "backup",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: opts.PVC}},
+		}}
+		podSpec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "backup", MountPath: opts.Root}}
+	}
+	backoff := int32(0)
+	ttl := int32(24 * 60 * 60)
+	return &batchv1.Job{
+		ObjectMeta: metaV1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoff,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metaV1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+		},
+	}, nil
+}
+
+// runJob 创建 Job 并等待完成，返回 Job Pod 的日志。Job 失败时错误中包含日志最后几行
+func runJob(ctx context.Context, client kubernetes.Interface, job *batchv1.Job, timeout time.Duration, progress io.Writer) (string, error) {
+	created, err := client.BatchV1().Jobs(job.Namespace).Create(ctx, job, metaV1.CreateOptions{})
+	if err != nil {
+		return "", i18n.Errorf("tidb.job_create_failed", job.Name, err)
+	}
+	fmt.Fprint(progress, i18n.T("tidb.job_created", created.Namespace, created.Name))
+	var failed bool
+	err = wait.PollUntilContextTimeout(ctx, 5*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		j, err := client.BatchV1().Jobs(created.Namespace).Get(ctx, created.Name, metaV1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, c := range j.Status.Conditions {
+			if c.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch c.Type {
+			case batchv1.JobComplete:
+				return true, nil
+			case batchv1.JobFailed:
+				failed = true
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", i18n.Errorf("tidb.job_wait_failed", created.Name, err)
+	}
+	logs, logErr := jobLogs(ctx, client, created)
+	if failed {
+		return logs, i18n.Errorf("tidb.job_failed", created.Namespace, created.Name, tail(logs, 10))
+	}
+	return logs, logErr
+}
+
+// jobLogs 读取 Job 第一个 Pod 的日志
+func jobLogs(ctx context.Context, client kubernetes.Interface, job *batchv1.Job) (string, error) {
+	pods, err := client.CoreV1().Pods(job.Namespace).List(ctx, metaV1.ListOptions{LabelSelector: "job-name=" + job.Name})
+	if err != nil {
+		return "", err
+	}
+	if len(pods.Items) == 0 {
+		return "", nil
+	}
+	stream, err := client.CoreV1().Pods(job.Namespace).GetLogs(pods.Items[0].Name, &corev1.PodLogOptions{}).Stream(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+	data, err := io.ReadAll(stream)
+	return string(data), err
+}
+
+// jobMeta 备份 Job 输出的元数据
+type jobMeta struct {
+	TSO      uint64
+	Size     int64
+	Checksum string
+}
+
+// parseMeta 从日志中最后一行 DEVOPS_TOOL_META 读取备份元数据
+func parseMeta(logs string) (jobMeta, bool) {
+	var m jobMeta
+	found := false
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != metaPrefix {
+			continue
+		}
+		m, found = jobMeta{}, true
+		for _, f := range fields[1:] {
+			key, value, _ := strings.Cut(f, "=")
+			switch key {
+			case "tso":
+				m.TSO, _ = strconv.ParseUint(value, 10, 64)
+			case "size":
+				m.Size, _ = strconv.ParseInt(value, 10, 64)
+			case "checksum":
+				if value != "" {
+					m.Checksum = "sha256:" + value
+				}
+			}
+		}
+	}
+	return m, found
+}
+
+func tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
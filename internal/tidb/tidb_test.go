@@ -0,0 +1,100 @@
+package tidb
+
+import (
+	"context"
+	"devops_tools/internal/mysql"
+	corev1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+func TestDiscoverFromServices(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metaV1.ObjectMeta{Name: "dol-tidb-pd", Namespace: "ns"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "peer", Port: 2380}, {Name: "client", Port: 2379}}},
+		},
+		&corev1.Service{
+			ObjectMeta: metaV1.ObjectMeta{Name: "dol-tidb-tidb", Namespace: "ns"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "sql", Port: 4000}}},
+		},
+	)
+	c, err := Discover(context.Background(), client, nil, mysql.Instance{Namespace: "ns", Name: "dol-tidb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.PD != "dol-tidb-pd.ns.svc:2379" || c.TiDB != "dol-tidb-tidb.ns.svc:4000" {
+		t.Errorf("Discover() = %+v", c)
+	}
+	if got := c.image(ToolDumpling, ""); got != "pingcap/dumpling:"+DefaultVersion {
+		t.Errorf("image() = %s", got)
+	}
+	if _, err := Discover(context.Background(), client, nil, mysql.Instance{Namespace: "ns", Name: "missing"}); err == nil {
+		t.Error("Discover() for a missing cluster returned no error")
+	}
+}
+
+func TestParseMeta(t *testing.T) {
+	m, ok := parseMeta("[INFO] dump finished\nDEVOPS_TOOL_META tso=455032781457145857 size=2048 checksum=ab12\n")
+	if !ok || m.TSO != 455032781457145857 || m.Size != 2048 || m.Checksum != "sha256:ab12" {
+		t.Errorf("parseMeta() = %+v, %v", m, ok)
+	}
+	if _, ok := parseMeta("no meta"); ok {
+		t.Error("parseMeta() found meta in logs without it")
+	}
+}
+
+func TestRecords(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	ctx := context.Background()
+	now := time.Now()
+	for i, name := range []string{"dol-tidb", "dol-tidb", "other"} {
+		rec := &Record{Name: name + "-backup-" + string(rune('a'+i)), Namespace: "ns", Instance: name, Tool: ToolDumpling,
+			Path: "/backup/20250117/test" + string(rune('a'+i)), Time: now.Add(time.Duration(i) * time.Minute)}
+		if err := saveRecord(ctx, client, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := ListBackups(ctx, client, "ns", "dol-tidb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Path != "/backup/20250117/testb" {
+		t.Fatalf("ListBackups() = %+v", records)
+	}
+	if rec, ok := FindBackup(records, "/backup/20250117/testa/"); !ok || rec.Instance != "dol-tidb" {
+		t.Errorf("FindBackup() = %+v, %v", rec, ok)
+	}
+}
+
+func TestRecordEntry(t *testing.T) {
+	cases := map[string]string{
+		"/backup/20250117/20250117020000_dol-tidb":         "/mnt/backup/20250117/20250117020000_dol-tidb",
+		"local:///backup/20250117/20250117020000_dol-tidb": "local:///mnt/backup/20250117/20250117020000_dol-tidb",
+		"s3://bucket/dol-tidb":                             "s3://bucket/dol-tidb",
+		"/data/20250117/20250117020000_dol-tidb":           "/data/20250117/20250117020000_dol-tidb",
+	}
+	for p, want := range cases {
+		rec := &Record{Namespace: "ns", Instance: "dol-tidb", Path: p}
+		if e := rec.Entry("/backup/", "/mnt/backup"); e.Path != want || e.Instance != "ns/dol-tidb" {
+			t.Errorf("Entry(%q) = %+v, want path %q", p, e, want)
+		}
+	}
+}
+
+func TestNewJobPasswordSecret(t *testing.T) {
+	job, err := newJob("ns", "dol-tidb-backup", "dol-tidb", "pingcap/dumpling:v7.5.0", "true",
+		JobOptions{PVC: "backup", Root: "/backup", PasswordSecret: "tidb-secret/root"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := job.Spec.Template.Spec.Containers[0]
+	if c.Env[0].ValueFrom.SecretKeyRef.Name != "tidb-secret" || c.VolumeMounts[0].MountPath != "/backup" {
+		t.Errorf("newJob() container = %+v", c)
+	}
+	if _, err := newJob("ns", "x", "x", "img", "true", JobOptions{PasswordSecret: "bad"}); err == nil {
+		t.Error("newJob() accepted a secret without key")
+	}
+}
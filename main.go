@@ -1,16 +1,52 @@
 package main
 
 import (
+	"devops_tools/cmd/backupCmd"
 	"devops_tools/cmd/clusterCmd"
+	"devops_tools/cmd/completionCmd"
+	"devops_tools/cmd/configCmd"
+	"devops_tools/cmd/controllerCmd"
+	"devops_tools/cmd/doctorCmd"
+	"devops_tools/cmd/exporterCmd"
+	"devops_tools/cmd/installCmd"
+	"devops_tools/cmd/mysqlCmd"
+	"devops_tools/cmd/policyCmd"
+	"devops_tools/cmd/tidbCmd"
+	"devops_tools/internal/api"
+	"devops_tools/internal/apperr"
+	"devops_tools/internal/completion"
+	"devops_tools/internal/config"
+	"devops_tools/internal/i18n"
 	"fmt"
 	"github.com/spf13/cobra"
 	"os"
+	"strings"
 )
 
+// cfg 在解析参数之前加载，语言和 flag 默认值都依赖它
+var cfg *config.Config
+var cfgErr error
+
 var rootCmd = &cobra.Command{
 	Use:     "devops-tool",
 	Short:   "devops-tool is a CLI tool",
 	Version: "V1.0.0",
+	// 配置文件中的取值作为未在命令行指定的 flag 的默认值
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// 未经过 main 直接调用 Execute 时（例如测试）按解析后的 --config 和 --profile 加载
+		if cfg == nil {
+			path, _ := cmd.Flags().GetString("config")
+			profile, _ := cmd.Flags().GetString("profile")
+			loadConfig(path, profile)
+		}
+		if cfgErr != nil {
+			return apperr.ValidationError(cfgErr)
+		}
+		return cfg.Apply(cmd)
+	},
+	// 错误统一在 main 中输出并转换为退出码
+	SilenceErrors: true,
+	SilenceUsage:  true,
 	Run: func(cmd *cobra.Command, args []string) {
 	},
 }
@@ -20,13 +56,77 @@ func Execute(rootCmd *cobra.Command) error {
 }
 
 func init() {
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return apperr.Validationf("%v\nRun '%s --help' for usage.", err, cmd.CommandPath())
+	})
 	rootCmd.AddCommand(clusterCmd.ClusterCmd())
+	rootCmd.AddCommand(exporterCmd.ExporterCmd())
+	rootCmd.AddCommand(controllerCmd.ControllerCmd())
+	rootCmd.AddCommand(policyCmd.PolicyCmd())
+	rootCmd.AddCommand(installCmd.InstallCmd())
+	rootCmd.AddCommand(doctorCmd.DoctorCmd())
+	rootCmd.AddCommand(configCmd.ConfigCmd())
+	rootCmd.AddCommand(mysqlCmd.MysqlCmd())
+	rootCmd.AddCommand(tidbCmd.TidbCmd())
+	rootCmd.AddCommand(backupCmd.BackupCmd())
+	rootCmd.AddCommand(completionCmd.CompletionCmd())
+	// 使用显式的 completion 命令替代 cobra 默认生成的命令
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	// 以下取值在 Execute 之前由 flagValue 读取，这里注册是为了让 cobra 接受这些参数并显示帮助
+	rootCmd.PersistentFlags().String("lang", "", "output language: zh-CN or en (default from LANG)")
+	rootCmd.PersistentFlags().String("config", "", "config file (default /etc/devops-tool/config.yaml overlaid by ~/.devops-tool.yaml)")
+	rootCmd.PersistentFlags().String("profile", "", "use a named profile from the config file")
+	rootCmd.PersistentFlags().StringVar(&api.Kubeconfig, "kubeconfig", "", "path to the kubeconfig file (default KUBECONFIG or ~/.kube/config)")
+	rootCmd.PersistentFlags().StringVar(&api.Context, "context", "", "kubeconfig context to use")
+	rootCmd.PersistentFlags().StringVar(&api.ClusterSign, "cluster-sign", "", "logical cluster name from the clusters registry in the config file, replaces --kubeconfig and --context")
+	_ = rootCmd.RegisterFlagCompletionFunc("context", completion.Contexts)
+	_ = rootCmd.RegisterFlagCompletionFunc("cluster-sign", completion.ClusterSigns)
+	_ = rootCmd.RegisterFlagCompletionFunc("lang", completion.Fixed("zh-CN", "en"))
+}
+
+// flagValue 在 cobra 解析之前从参数中读取 --name value 或 --name=value
+func flagValue(args []string, name string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--"+name+"="); ok {
+			return value
+		}
+		if arg == "--"+name && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// loadConfig 加载配置文件，失败时使用空配置，错误由 PersistentPreRunE 返回，
+// 这样 --help 和 config 子命令在配置有误时仍然可用
+func loadConfig(path, profile string) {
+	cfg, cfgErr = config.Load(path, profile)
+	if cfgErr != nil {
+		cfg = &config.Config{}
+	}
 }
 
 func main() {
+	args := os.Args[1:]
+	loadConfig(flagValue(args, "config"), flagValue(args, "profile"))
+	lang := flagValue(args, "lang")
+	if lang == "" {
+		lang = cfg.Lang
+	}
+	if err := i18n.Init(lang); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(apperr.Validation.ExitCode())
+	}
+	i18n.LocalizeCommands(rootCmd)
+	// 补全请求不会执行 PersistentPreRunE，预先使用配置中的集群连接参数，命令行 flag 在解析时覆盖
+	api.Kubeconfig, api.Context = cfg.Kubeconfig, cfg.Context
+	api.ClusterSign, api.Clusters = cfg.ClusterSign, cfg.Clusters
 	err := Execute(rootCmd)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(apperr.ExitCode(err))
 	}
 }
@@ -2,8 +2,10 @@ package main
 
 import (
 	"bytes"
+	"devops_tools/internal/apperr"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -29,3 +31,31 @@ func TestHelloCommand(t *testing.T) {
 	}
 
 }
+
+func TestExecuteLoadsConfig(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "config.yaml")
+	invalid := filepath.Join(dir, "invalid.yaml")
+	os.WriteFile(valid, []byte("lang: en\n"), 0644)
+	os.WriteFile(invalid, []byte("qps: [\n"), 0644)
+	t.Cleanup(func() {
+		cfg, cfgErr = nil, nil
+		rootCmd.SetArgs(nil)
+	})
+
+	// 不经过 main 时由 PersistentPreRunE 加载配置，子命令可以正常执行
+	cfg, cfgErr = nil, nil
+	rootCmd.SetArgs([]string{"--config", valid, "install", "render", "--mode", "cronjob"})
+	if err := Execute(rootCmd); err != nil {
+		t.Fatalf("Execute(install render) error = %v", err)
+	}
+	if cfg == nil || len(cfg.Sources) != 1 || cfg.Sources[0] != valid {
+		t.Errorf("config not loaded from %s: %+v", valid, cfg)
+	}
+
+	cfg, cfgErr = nil, nil
+	rootCmd.SetArgs([]string{"--config", invalid, "install", "render", "--mode", "cronjob"})
+	if err := Execute(rootCmd); apperr.ExitCode(err) != apperr.Validation.ExitCode() {
+		t.Errorf("Execute() with invalid config = %v, want validation error", err)
+	}
+}